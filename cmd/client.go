@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"log"
+	"os"
+	"time"
 
 	"github.com/sarangjo/rlesports/internal/rlesports"
 	"github.com/spf13/cobra"
@@ -9,44 +11,167 @@ import (
 
 var jsonStorage rlesports.JsonStorage
 
+var liquipedia = rlesports.NewLiquipediaClient()
+
+var requestMetrics rlesports.RequestMetrics
+
+// Response cache flags
+var (
+	noCache  bool
+	refresh  bool
+	cacheTTL time.Duration
+)
+
+// Record/replay flags
+var (
+	recordDir string
+	replayDir string
+)
+
+// Discovery flags
+var (
+	discoverTiers []string
+	discoverOut   string
+)
+
+// Series to update; all registered series if empty
+var seriesNames []string
+
 var clientCmd = &cobra.Command{
 	Use: "client",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if recordDir != "" && replayDir != "" {
+			log.Fatalf("--record and --replay can't be used together")
+		}
+		if recordDir != "" {
+			liquipedia.HTTPClient.Transport = &rlesports.Recorder{Dir: recordDir}
+		} else if replayDir != "" {
+			// No network means nothing to rate limit
+			liquipedia.HTTPClient.Transport = &rlesports.Replayer{Dir: replayDir}
+			liquipedia.Limiter = nil
+			liquipedia.MaxRetries = 0
+		}
+
+		// Cached responses would never reach the recorder or replayer
+		if !noCache && recordDir == "" && replayDir == "" {
+			liquipedia.Cache = rlesports.NewResponseCache(rlesports.DefaultCacheDir, cacheTTL)
+			liquipedia.Cache.Refresh = refresh
+		}
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		requestMetrics.Summary(os.Stdout, 10)
+	},
 }
 
 var tournamentCmd = &cobra.Command{
 	Use: "tournaments",
 	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
 		switch args[0] {
 		case "updateall":
-			rlesports.UpdateTournaments(jsonStorage, 2, false)
+			registerSeries()
+			series := rlesports.RegisteredSeries()
+			if len(seriesNames) > 0 {
+				series = nil
+				for _, name := range seriesNames {
+					s, ok := rlesports.LookupSeries(name)
+					if !ok {
+						log.Fatalf("Unknown series %q, expected one of %v", name, rlesports.SeriesNames())
+					}
+					series = append(series, s)
+				}
+			}
+			if err := rlesports.UpdateTournaments(ctx, liquipedia, jsonStorage, series, 2, false); err != nil {
+				log.Fatalf("Updating tournaments failed: %v", err)
+			}
 		case "update":
 			if len(args) < 2 {
 				log.Fatalf("Not enough arguments provided")
 			}
-			rlesports.UpdateTournament(jsonStorage, rlesports.Tournament{
+			err := rlesports.UpdateTournament(ctx, liquipedia, jsonStorage, rlesports.Tournament{
 				Name: args[1],
 			}, false)
+			if err != nil {
+				log.Fatalf("Could not update %v: %v", args[1], err)
+			}
+		case "discover":
+			registerSeries()
+			discovered, err := rlesports.DiscoverTournaments(ctx, liquipedia, discoverTiers)
+			if err != nil {
+				log.Fatalf("Could not discover tournaments: %v", err)
+			}
+			stored, err := jsonStorage.GetAllTournaments(ctx)
+			if err != nil {
+				log.Fatalf("Could not get stored tournaments: %v", err)
+			}
+			rlesports.DiffTournaments(discovered, stored).Summary(os.Stdout)
+			if discoverOut != "" {
+				if err := rlesports.WriteJSONFile(discovered, discoverOut); err != nil {
+					log.Fatalf("Could not write discovered tournaments: %v", err)
+				}
+			}
 		case "refreshjson":
 			t, err := rlesports.JsonGetTournaments()
 			if err != nil {
-				log.Fatalf("Could not get tournaments from JSON")
+				log.Fatalf("Could not get tournaments from JSON: %v", err)
+			}
+			if err := rlesports.JsonSaveTournaments(t); err != nil {
+				log.Fatalf("Could not save tournaments to JSON: %v", err)
 			}
-			rlesports.JsonSaveTournaments(t)
 		}
 	},
 }
 
+// registerSeries adds the configured series to the RLCS, for the commands that go through every
+// series
+func registerSeries() {
+	if err := rlesports.RegisterConfiguredSeries(); err != nil {
+		log.Fatalf("Could not load series: %v", err)
+	}
+}
+
 var playersCmd = &cobra.Command{
 	Use: "players",
 	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
 		switch args[0] {
 		case "updateall":
-			rlesports.UpdatePlayerNames(jsonStorage)
+			if err := rlesports.UpdatePlayerNames(ctx, liquipedia, jsonStorage); err != nil {
+				log.Fatalf("Updating players failed: %v", err)
+			}
 		case "fetch":
-			wikitext := rlesports.FetchPlayer("kronovi")
-			log.Println(wikitext)
-			redirect, to := rlesports.IsRedirectTo(wikitext)
-			log.Println(redirect, to)
+			page := "kronovi"
+			if len(args) > 1 {
+				page = args[1]
+			}
+			player, err := rlesports.FetchPlayerDetails(ctx, liquipedia, page)
+			if err != nil {
+				log.Fatalf("Could not fetch player: %v", err)
+			}
+			log.Printf("%+v", player)
 		}
 	},
 }
+
+func init() {
+	liquipedia.Progress = func(event rlesports.ProgressEvent) {
+		log.Println(event)
+	}
+	liquipedia.OnRequest = requestMetrics.Record
+
+	clientCmd.PersistentFlags().StringVar(&liquipedia.BaseURL, "api-base", rlesports.DefaultAPIBase, "Liquipedia API endpoint")
+	clientCmd.PersistentFlags().StringVar(&liquipedia.UserAgent, "user-agent", rlesports.DefaultUserAgent, "User agent sent to Liquipedia")
+	clientCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Always call the API and don't store responses")
+	clientCmd.PersistentFlags().BoolVar(&refresh, "refresh", false, "Ignore cached responses but store fresh ones")
+	clientCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", rlesports.DefaultCacheTTL, "How long cached responses stay fresh")
+	clientCmd.PersistentFlags().IntVar(&liquipedia.MaxRetries, "max-retries", liquipedia.MaxRetries, "How many times to retry rate limited or failed requests")
+	clientCmd.PersistentFlags().Int64Var(&liquipedia.MaxResponseSize, "max-response-size", liquipedia.MaxResponseSize, "Largest response body accepted, in bytes")
+	clientCmd.PersistentFlags().StringVar(&recordDir, "record", "", "Save every API response as a fixture in this directory")
+	clientCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "Serve API responses from fixtures in this directory instead of the network")
+
+	tournamentCmd.Flags().StringSliceVar(&discoverTiers, "tier", rlesports.DefaultTiers, "Tiers of Portal:Tournaments to discover")
+	tournamentCmd.Flags().StringVar(&discoverOut, "out", "", "Write discovered tournament skeletons to this JSON file")
+	tournamentCmd.Flags().StringSliceVar(&seriesNames, "series", nil, "Series to update, e.g. RLCS (default all)")
+}
@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 
 	"github.com/spf13/cobra"
 )
@@ -16,8 +19,13 @@ var (
 	}
 )
 
+// Execute runs the root command. Ctrl-C cancels the command's context so that long-running
+// updates can stop cleanly.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return rootCmd.ExecuteContext(ctx)
 }
 
 func init() {
@@ -48,7 +48,15 @@ var serverCmd = &cobra.Command{
 
 			fmt.Println("mongo client initialized")
 
-			http.HandleFunc("/api/tournaments", func(w http.ResponseWriter, r *http.Request) { handle(w, r, rlesportsdb.GetTournaments()) })
+			http.HandleFunc("/api/tournaments", func(w http.ResponseWriter, r *http.Request) {
+				// e.g. ?region=eu
+				var region rlesports.Region
+				if err := region.UnmarshalText([]byte(r.URL.Query().Get("region"))); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				handle(w, r, rlesportsdb.GetTournaments(region))
+			})
 			// http.HandleFunc("/api/seasons", func(w http.ResponseWriter, r *http.Request) { handle(w, r, rlesportsdb.GetSeasons()) })
 			http.HandleFunc("/", home)
 
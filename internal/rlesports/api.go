@@ -3,30 +3,97 @@ package rlesports
 // Lightweight wrappers around Liquipedia API calls
 
 import (
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
-const apiBase = "https://liquipedia.net/rocketleague/api.php"
-const userAgent = "RL Esports"
-const rateGap time.Duration = time.Second * 10
+const (
+	DefaultAPIBase   = "https://liquipedia.net/rocketleague/api.php"
+	DefaultUserAgent = "RL Esports"
+)
+
+// Retry defaults: waits double from the base on each attempt, unless Liquipedia asks for longer
+const (
+	defaultMaxRetries = 4
+	defaultRetryBase  = 5 * time.Second
+)
+
+// DefaultMaxResponseSize is well above the largest pages we parse, but stops a runaway response
+// (or a gzip bomb) from eating all our memory
+const DefaultMaxResponseSize int64 = 32 << 20
 
-var httpClient = &http.Client{}
-var lastRequest = time.Unix(0, 0)
+// LiquipediaClient makes requests against a Liquipedia (MediaWiki) API endpoint
+type LiquipediaClient struct {
+	BaseURL    string
+	UserAgent  string
+	HTTPClient *http.Client
+	// Limiter spaces out requests; nil disables rate limiting
+	Limiter *RateLimiter
+	// MaxRetries is how many times a rate limited or failed request is retried
+	MaxRetries int
+	// RetryBase is the wait before the first retry
+	RetryBase time.Duration
+	// MaxResponseSize is the most (decompressed) bytes read from a single response
+	MaxResponseSize int64
+	// Progress, if set, is told about waits and retries
+	Progress func(ProgressEvent)
+	// OnRequest, if set, is told about every request, including ones served from the cache
+	OnRequest func(RequestStats)
+	// Cache serves repeated requests from disk; nil disables caching
+	Cache *ResponseCache
+}
+
+// NewLiquipediaClient creates a client for the Rocket League wiki with the default rate limit
+func NewLiquipediaClient() *LiquipediaClient {
+	return &LiquipediaClient{
+		BaseURL:    DefaultAPIBase,
+		UserAgent:  DefaultUserAgent,
+		HTTPClient: &http.Client{},
+		Limiter:    NewRateLimiter(),
+		MaxRetries: defaultMaxRetries,
+		RetryBase:  defaultRetryBase,
+
+		MaxResponseSize: DefaultMaxResponseSize,
+	}
+}
 
 type parseResult struct {
 	Parse interface{} `json:"parse"`
+	Error *APIError   `json:"error"`
+}
+
+// callParse runs an action=parse request and returns the decoded "parse" object
+func (c *LiquipediaClient) callParse(ctx context.Context, opts url.Values) (interface{}, error) {
+	resp, err := c.CallAPI(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var res parseResult
+	if err := json.Unmarshal(resp, &res); err != nil {
+		return nil, malformed("%v", err)
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.Parse == nil {
+		return nil, malformed("no parse result for %v", opts.Get("page"))
+	}
+	return res.Parse, nil
 }
 
 // FetchPlayer gets player information. If we find a redirect, return it as first parameter; otherwise
 // it is empty string.
-func FetchPlayer(player string) (wikitext string) {
+func (c *LiquipediaClient) FetchPlayer(ctx context.Context, player string) (wikitext string, err error) {
 	opts := url.Values{
 		"action":  {"parse"},
 		"prop":    {"wikitext"},
@@ -34,88 +101,332 @@ func FetchPlayer(player string) (wikitext string) {
 		"format":  {"json"},
 		"section": {"0"},
 	}
-	var res parseResult
-	resp := CallAPI(opts)
-	json.Unmarshal(resp, &res)
+	parse, err := c.callParse(ctx, opts)
+	if err != nil {
+		return "", fmt.Errorf("fetching player %v: %w", player, err)
+	}
 
-	return ExtractWikitext(res.Parse)
+	return ExtractWikitext(parse)
 }
 
-// FetchSection gets the section wikitext for the given page and section
-func FetchSection(page string, section int) (wikitext string) {
+// ExpandTemplates expands every template in text as if it were on the page title, e.g.
+// {{TeamHistoryAuto}} on a player's page, whose output depends on data that isn't in the page's own
+// wikitext
+func (c *LiquipediaClient) ExpandTemplates(ctx context.Context, title string, text string) (wikitext string, err error) {
 	opts := url.Values{
-		"action":  {"parse"},
-		"prop":    {"wikitext"},
-		"page":    {page},
-		"section": {strconv.Itoa(section)},
+		"action": {"expandtemplates"},
+		"prop":   {"wikitext"},
+		"title":  {title},
+		"text":   {text},
+		"format": {"json"},
 	}
-	var res parseResult
-	resp := CallAPI(opts)
-	json.Unmarshal(resp, &res)
+	resp, err := c.CallAPI(ctx, opts)
+	if err != nil {
+		return "", fmt.Errorf("expanding %v on %v: %w", text, title, err)
+	}
+
+	var res struct {
+		ExpandTemplates *struct {
+			Wikitext string `json:"wikitext"`
+		} `json:"expandtemplates"`
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(resp, &res); err != nil {
+		return "", fmt.Errorf("expanding %v on %v: %w", text, title, malformed("%v", err))
+	}
+	if res.Error != nil {
+		return "", fmt.Errorf("expanding %v on %v: %w", text, title, res.Error)
+	}
+	if res.ExpandTemplates == nil {
+		return "", fmt.Errorf("expanding %v on %v: %w", text, title, malformed("no expandtemplates result"))
+	}
+	return res.ExpandTemplates.Wikitext, nil
+}
+
+// FetchSection gets the section wikitext for the given page and section
+func (c *LiquipediaClient) FetchSection(ctx context.Context, page string, section int) (wikitext string, err error) {
+	wikitext, err = c.fetchSection(ctx, url.Values{"page": {page}}, section)
+	if err != nil {
+		return "", fmt.Errorf("fetching section %d of %v: %w", section, page, err)
+	}
+	return wikitext, nil
+}
+
+// FetchRevisionSection gets the section wikitext for a specific revision of a page. Unlike
+// FetchSection, a cached response can never be stale since revisions don't change.
+func (c *LiquipediaClient) FetchRevisionSection(ctx context.Context, revID int64, section int) (wikitext string, err error) {
+	wikitext, err = c.fetchSection(ctx, url.Values{"oldid": {strconv.FormatInt(revID, 10)}}, section)
+	if err != nil {
+		return "", fmt.Errorf("fetching section %d of revision %d: %w", section, revID, err)
+	}
+	return wikitext, nil
+}
+
+// FetchPage gets the wikitext of the whole page
+func (c *LiquipediaClient) FetchPage(ctx context.Context, page string) (wikitext string, err error) {
+	wikitext, err = c.fetchSection(ctx, url.Values{"page": {page}}, wholePage)
+	if err != nil {
+		return "", fmt.Errorf("fetching %v: %w", page, err)
+	}
+	return wikitext, nil
+}
 
-	return ExtractWikitext(res.Parse)
+// FetchRevision gets the wikitext of a whole page as of a specific revision
+func (c *LiquipediaClient) FetchRevision(ctx context.Context, revID int64) (wikitext string, err error) {
+	wikitext, err = c.fetchSection(ctx, url.Values{"oldid": {strconv.FormatInt(revID, 10)}}, wholePage)
+	if err != nil {
+		return "", fmt.Errorf("fetching revision %d: %w", revID, err)
+	}
+	return wikitext, nil
+}
+
+// wholePage can be passed to fetchSection to get every section
+const wholePage = -1
+
+// fetchSection gets section wikitext for the page identified by opts ("page" or "oldid")
+func (c *LiquipediaClient) fetchSection(ctx context.Context, opts url.Values, section int) (string, error) {
+	opts.Set("action", "parse")
+	opts.Set("prop", "wikitext")
+	if section != wholePage {
+		opts.Set("section", strconv.Itoa(section))
+	}
+	parse, err := c.callParse(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return ExtractWikitext(parse)
 }
 
 // FetchSections gets all sections for the given page
-func FetchSections(page string) []map[string]interface{} {
-	opts := url.Values{
-		"action": {"parse"},
-		"prop":   {"sections"},
-		"page":   {page},
+func (c *LiquipediaClient) FetchSections(ctx context.Context, page string) ([]map[string]interface{}, error) {
+	sections, err := c.fetchSections(ctx, url.Values{"page": {page}})
+	if err != nil {
+		return nil, fmt.Errorf("fetching sections of %v: %w", page, err)
+	}
+	return sections, nil
+}
+
+// FetchRevisionSections gets all sections for a specific revision of a page
+func (c *LiquipediaClient) FetchRevisionSections(ctx context.Context, revID int64) ([]map[string]interface{}, error) {
+	sections, err := c.fetchSections(ctx, url.Values{"oldid": {strconv.FormatInt(revID, 10)}})
+	if err != nil {
+		return nil, fmt.Errorf("fetching sections of revision %d: %w", revID, err)
+	}
+	return sections, nil
+}
+
+// fetchSections gets all sections for the page identified by opts ("page" or "oldid")
+func (c *LiquipediaClient) fetchSections(ctx context.Context, opts url.Values) ([]map[string]interface{}, error) {
+	opts.Set("action", "parse")
+	opts.Set("prop", "sections")
+	parse, err := c.callParse(ctx, opts)
+	if err != nil {
+		return nil, err
 	}
-	var res parseResult
-	resp := CallAPI(opts)
-	json.Unmarshal(resp, &res)
 
 	// Can't type assert a slice
-	rawSections := res.Parse.(map[string]interface{})["sections"].([]interface{})
+	parseMap, ok := parse.(map[string]interface{})
+	if !ok {
+		return nil, malformed("parse result is not an object")
+	}
+	rawSections, ok := parseMap["sections"].([]interface{})
+	if !ok {
+		return nil, malformed("parse result has no sections")
+	}
 	sections := make([]map[string]interface{}, 0, len(rawSections))
 	for _, raw := range rawSections {
-		sections = append(sections, raw.(map[string]interface{}))
+		section, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, malformed("section is not an object")
+		}
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+// CallAPI calls Liquipedia API. Successful responses are served from and saved to the client's
+// cache, if it has one.
+func (c *LiquipediaClient) CallAPI(ctx context.Context, opts url.Values) ([]byte, error) {
+	return c.call(ctx, opts, true)
+}
+
+// call calls the API, going through the cache only if cacheable is set. Requests whose answers must
+// always be current (e.g. revision checks) shouldn't be cacheable.
+func (c *LiquipediaClient) call(ctx context.Context, opts url.Values, cacheable bool) ([]byte, error) {
+	opts.Set("origin", "*")
+	opts.Set("format", "json")
+	if !cacheable {
+		return c.fetch(ctx, opts)
+	}
+
+	key := cacheKey(c.BaseURL, opts)
+	if body, ok := c.Cache.Get(key); ok {
+		c.record(RequestStats{Action: opts.Get("action"), Target: requestTarget(opts), Bytes: int64(len(body)), Cached: true})
+		return body, nil
+	}
+
+	body, err := c.fetch(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Error payloads aren't cached, e.g. a missing page might be created before the next run
+	var res struct {
+		Error *APIError `json:"error"`
 	}
-	return sections
+	if json.Unmarshal(body, &res) == nil && res.Error == nil {
+		if err := c.Cache.Put(key, body); err != nil {
+			fmt.Println("Unable to cache response", err)
+		}
+	}
+
+	return body, nil
 }
 
-// CallAPI calls Liquipedia API
-func CallAPI(opts url.Values) []byte {
-	// Rate limit
-	timeSinceLast := time.Since(lastRequest)
-	if timeSinceLast < rateGap {
-		fmt.Printf("waiting for %v\n", (rateGap - timeSinceLast).Round(time.Second))
-		for time.Since(lastRequest) < rateGap {
-			time.Sleep(time.Second * 5)
-			fmt.Print(".")
+// fetch makes the actual HTTP request to the API, retrying with exponential backoff when
+// Liquipedia is rate limiting us or having trouble
+func (c *LiquipediaClient) fetch(ctx context.Context, opts url.Values) ([]byte, error) {
+	action := opts.Get("action")
+
+	for attempt := 1; ; attempt++ {
+		if wait := c.Limiter.Reserve(action); wait > 0 {
+			c.progress(ProgressEvent{Kind: ProgressWait, Action: action, Wait: wait})
+			if err := sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+		}
+
+		body, retryAfter, err := c.do(ctx, opts)
+		if err == nil {
+			return body, nil
+		}
+		if retryAfter < 0 || attempt > c.MaxRetries {
+			return nil, err
+		}
+
+		wait := c.RetryBase << (attempt - 1)
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		c.progress(ProgressEvent{Kind: ProgressRetry, Action: action, Wait: wait, Attempt: attempt, Err: err})
+		// Hold back everyone else using the limiter too, not just this request
+		c.Limiter.Backoff(action, wait)
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
 		}
-		fmt.Println()
 	}
+}
 
-	u, err := url.Parse(apiBase)
+// do makes a single request. If it fails, retryAfter says whether it's worth retrying: negative means
+// no, otherwise it's the minimum wait the server asked for (which may be zero).
+func (c *LiquipediaClient) do(ctx context.Context, opts url.Values) (body []byte, retryAfter time.Duration, err error) {
+	u, err := url.Parse(c.BaseURL)
 	if err != nil {
-		fmt.Println("Could not parse api base... wut?")
-		os.Exit(1)
+		return nil, -1, fmt.Errorf("%w: %v", ErrTransport, err)
 	}
-	opts.Set("origin", "*")
-	opts.Set("format", "json")
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s?%s", u.String(), opts.Encode()), nil)
+	u.RawQuery = opts.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		fmt.Println("Failed to create request")
+		return nil, -1, fmt.Errorf("%w: %v", ErrTransport, err)
 	}
-	req.Header.Add("user-agent", userAgent)
+	req.Header.Add("user-agent", c.UserAgent)
+	// Liquipedia asks for gzip. Setting this ourselves turns off net/http's transparent
+	// decompression, so we decode below and get to see the compressed size.
+	req.Header.Set("Accept-Encoding", "gzip")
 
-	resp, err := httpClient.Do(req)
+	stats := RequestStats{Action: opts.Get("action"), Target: requestTarget(opts)}
+	started := time.Now()
+	defer func() {
+		stats.Duration = time.Since(started)
+		stats.Bytes = int64(len(body))
+		c.record(stats)
+	}()
+
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		fmt.Println("Failed to get API", err)
-		os.Exit(1)
+		// Cancellation isn't a transport problem, so hand it back as is
+		if ctx.Err() != nil {
+			return nil, -1, ctx.Err()
+		}
+		// Nor is replaying a request that was never recorded, and retrying won't record it
+		if errors.Is(err, ErrNoFixture) {
+			return nil, -1, err
+		}
+		return nil, 0, fmt.Errorf("%w: %v", ErrTransport, err)
 	}
 	defer resp.Body.Close()
+	stats.Status = resp.StatusCode
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), ErrRateLimited
+	} else if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), fmt.Errorf("%w: %v", ErrTransport, resp.Status)
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, -1, fmt.Errorf("%w: %v", ErrTransport, resp.Status)
+	}
 
-	body, err := io.ReadAll(resp.Body)
+	wire := &countingReader{r: resp.Body}
+	var reader io.Reader = wire
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		stats.Compressed = true
+		gz, err := gzip.NewReader(wire)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: %v", ErrTransport, err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	// Read one byte past the limit so that we can tell a body that is exactly at the limit from
+	// one that is over it
+	body, err = io.ReadAll(io.LimitReader(reader, c.MaxResponseSize+1))
+	stats.WireBytes = wire.n
 	if err != nil {
-		fmt.Println("Failed to read body", err)
-		os.Exit(1)
+		if ctx.Err() != nil {
+			return nil, -1, ctx.Err()
+		}
+		return nil, 0, fmt.Errorf("%w: %v", ErrTransport, err)
+	}
+	if int64(len(body)) > c.MaxResponseSize {
+		return nil, -1, fmt.Errorf("%w: over %d bytes", ErrResponseTooLarge, c.MaxResponseSize)
+	}
+
+	// Liquipedia can also rate limit with a regular response carrying an error payload
+	var res struct {
+		Error *APIError `json:"error"`
+	}
+	if json.Unmarshal(body, &res) == nil && res.Error != nil && errors.Is(res.Error, ErrRateLimited) {
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), res.Error
 	}
 
-	lastRequest = time.Now()
+	return body, 0, nil
+}
 
-	return body
+// parseRetryAfter reads a Retry-After header, which is either a number of seconds or a date
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(when); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+func (c *LiquipediaClient) progress(event ProgressEvent) {
+	if c.Progress != nil {
+		c.Progress(event)
+	}
+}
+
+func (c *LiquipediaClient) record(stats RequestStats) {
+	if c.OnRequest != nil {
+		c.OnRequest(stats)
+	}
 }
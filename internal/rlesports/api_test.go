@@ -0,0 +1,156 @@
+package rlesports
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestClient points a client at handler with no rate limiting and near-instant retries
+func newTestClient(t *testing.T, handler http.HandlerFunc) *LiquipediaClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewLiquipediaClient()
+	client.BaseURL = server.URL
+	client.HTTPClient = server.Client()
+	client.Limiter = nil
+	client.RetryBase = time.Millisecond
+	return client
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	for _, tc := range []struct {
+		code string
+		want error
+	}{
+		{"missingtitle", ErrPageMissing},
+		{"nosuchsection", ErrPageMissing},
+		{"nosuchrevid", ErrPageMissing},
+		{"invalidtitle", ErrPageMissing},
+		{"ratelimited", ErrRateLimited},
+		{"badvalue", nil},
+	} {
+		err := fmt.Errorf("wrapped: %w", &APIError{Code: tc.code, Info: "info"})
+		for _, sentinel := range []error{ErrPageMissing, ErrRateLimited, ErrMalformedResponse, ErrTransport} {
+			if got := errors.Is(err, sentinel); got != (sentinel == tc.want) {
+				t.Errorf("%v: errors.Is(%v) = %v", tc.code, sentinel, got)
+			}
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != tc.code {
+			t.Errorf("%v: errors.As = %v", tc.code, apiErr)
+		}
+	}
+}
+
+func TestFetchSectionErrors(t *testing.T) {
+	for _, tc := range []struct {
+		body string
+		want error
+	}{
+		{`{"error":{"code":"missingtitle","info":"The page you specified doesn't exist."}}`, ErrPageMissing},
+		{`{"error":{"code":"nosuchsection","info":"There is no section 7."}}`, ErrPageMissing},
+		{`{"parse":{"title":"A"}}`, ErrMalformedResponse},
+		{`not json`, ErrMalformedResponse},
+		{`{}`, ErrMalformedResponse},
+	} {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, tc.body)
+		})
+		if _, err := client.FetchSection(context.Background(), "A", 7); !errors.Is(err, tc.want) {
+			t.Errorf("%v: got %v, want %v", tc.body, err, tc.want)
+		}
+	}
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	const limit = 1 << 10
+	wikitext := func(n int) string {
+		// A JSON body of exactly n bytes, which compresses to almost nothing
+		prefix, suffix := `{"parse":{"wikitext":{"*":"`, `"}}}`
+		return prefix + strings.Repeat("a", n-len(prefix)-len(suffix)) + suffix
+	}
+
+	for _, tc := range []struct {
+		size    int
+		gzipped bool
+		tooBig  bool
+	}{
+		{limit, false, false},
+		{limit + 1, false, true},
+		{limit, true, false},
+		{limit + 1, true, true},
+		// A small compressed body that decompresses to far more than the limit
+		{100 * limit, true, true},
+	} {
+		body := wikitext(tc.size)
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Accept-Encoding") != "gzip" {
+				t.Errorf("Accept-Encoding = %q", r.Header.Get("Accept-Encoding"))
+			}
+			if !tc.gzipped {
+				fmt.Fprint(w, body)
+				return
+			}
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			gz.Write([]byte(body))
+			gz.Close()
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(buf.Bytes())
+		})
+		client.MaxResponseSize = limit
+
+		var stats []RequestStats
+		client.OnRequest = func(s RequestStats) { stats = append(stats, s) }
+
+		_, err := client.FetchSection(context.Background(), "A", 0)
+		if tc.tooBig != errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("%d bytes, gzipped %v: got %v", tc.size, tc.gzipped, err)
+		}
+		// Too large is final, so there's exactly one request either way
+		if len(stats) != 1 || stats[0].Compressed != tc.gzipped {
+			t.Errorf("%d bytes, gzipped %v: stats %+v", tc.size, tc.gzipped, stats)
+		} else if !tc.tooBig && tc.gzipped && stats[0].WireBytes >= stats[0].Bytes {
+			t.Errorf("compressed %d bytes into %d", stats[0].Bytes, stats[0].WireBytes)
+		}
+	}
+}
+
+func TestCallAPIParameters(t *testing.T) {
+	var got url.Values
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		if ua := r.Header.Get("User-Agent"); ua != "test agent" {
+			t.Errorf("User-Agent = %q", ua)
+		}
+		fmt.Fprint(w, `{"parse":{"wikitext":{"*":"text"}}}`)
+	})
+	client.UserAgent = "test agent"
+
+	wikitext, err := client.FetchRevisionSection(context.Background(), 1234, 2)
+	if err != nil || wikitext != "text" {
+		t.Fatalf("got %q, %v", wikitext, err)
+	}
+	want := url.Values{
+		"action":  {"parse"},
+		"prop":    {"wikitext"},
+		"oldid":   {"1234"},
+		"section": {"2"},
+		"format":  {"json"},
+		"origin":  {"*"},
+	}
+	if got.Encode() != want.Encode() {
+		t.Errorf("query = %v, want %v", got.Encode(), want.Encode())
+	}
+}
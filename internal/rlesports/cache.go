@@ -0,0 +1,80 @@
+package rlesports
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+/* On-disk cache of raw API responses */
+
+const (
+	DefaultCacheDir = "cache/responses"
+	DefaultCacheTTL = 24 * time.Hour
+)
+
+// ResponseCache stores API response bodies on disk, keyed on a hash of the request parameters
+type ResponseCache struct {
+	Dir string
+	// TTL is how long an entry stays fresh; zero means entries never expire
+	TTL time.Duration
+	// Refresh ignores existing entries but still stores new responses
+	Refresh bool
+}
+
+// NewResponseCache creates a cache in dir whose entries expire after ttl
+func NewResponseCache(dir string, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{Dir: dir, TTL: ttl}
+}
+
+// cacheKey is the content address of a request: the endpoint plus its encoded (sorted) parameters
+func cacheKey(base string, opts url.Values) string {
+	sum := sha256.Sum256([]byte(base + "?" + opts.Encode()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (rc *ResponseCache) path(key string) string {
+	return filepath.Join(rc.Dir, key+".json")
+}
+
+// Get returns the cached body for key if there is a fresh entry. A nil cache never hits.
+func (rc *ResponseCache) Get(key string) ([]byte, bool) {
+	if rc == nil || rc.Refresh {
+		return nil, false
+	}
+
+	info, err := os.Stat(rc.path(key))
+	if err != nil {
+		return nil, false
+	}
+	if rc.TTL > 0 && time.Since(info.ModTime()) > rc.TTL {
+		return nil, false
+	}
+
+	body, err := os.ReadFile(rc.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// Put stores body under key. A nil cache stores nothing.
+func (rc *ResponseCache) Put(key string, body []byte) error {
+	if rc == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(rc.Dir, fs.FileMode(0755)); err != nil {
+		return err
+	}
+	// Write then rename so that an interrupted run never leaves a truncated entry behind
+	tmp := rc.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, body, fs.FileMode(0644)); err != nil {
+		return err
+	}
+	return os.Rename(tmp, rc.path(key))
+}
@@ -0,0 +1,118 @@
+package rlesports
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	cache := NewResponseCache(t.TempDir(), time.Hour)
+	key := cacheKey(DefaultAPIBase, url.Values{"page": {"A"}})
+
+	if _, ok := cache.Get(key); ok {
+		t.Error("hit on an empty cache")
+	}
+	if err := cache.Put(key, []byte("body")); err != nil {
+		t.Fatal(err)
+	}
+	if body, ok := cache.Get(key); !ok || string(body) != "body" {
+		t.Errorf("Get = %q, %v", body, ok)
+	}
+
+	// Refresh skips entries without removing them
+	cache.Refresh = true
+	if _, ok := cache.Get(key); ok {
+		t.Error("hit while refreshing")
+	}
+	cache.Refresh = false
+
+	// Entries older than the TTL are stale...
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(cache.path(key), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get(key); ok {
+		t.Error("hit on a stale entry")
+	}
+	// ...unless entries never expire
+	cache.TTL = 0
+	if _, ok := cache.Get(key); !ok {
+		t.Error("miss with no TTL")
+	}
+
+	var nilCache *ResponseCache
+	if err := nilCache.Put(key, []byte("body")); err != nil {
+		t.Error(err)
+	}
+	if _, ok := nilCache.Get(key); ok {
+		t.Error("hit on a nil cache")
+	}
+}
+
+func TestResponseCacheKey(t *testing.T) {
+	a := cacheKey(DefaultAPIBase, url.Values{"page": {"A"}, "prop": {"wikitext"}})
+	if b := cacheKey(DefaultAPIBase, url.Values{"prop": {"wikitext"}, "page": {"A"}}); a != b {
+		t.Error("parameter order changed the key")
+	}
+	if b := cacheKey("http://localhost/api.php", url.Values{"page": {"A"}, "prop": {"wikitext"}}); a == b {
+		t.Error("endpoint didn't change the key")
+	}
+	if b := cacheKey(DefaultAPIBase, url.Values{"page": {"B"}, "prop": {"wikitext"}}); a == b {
+		t.Error("page didn't change the key")
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	requests := make(map[string]int)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page") + r.URL.Query().Get("titles")
+		requests[page]++
+		switch page {
+		case "Missing":
+			fmt.Fprint(w, `{"error":{"code":"missingtitle","info":"The page you specified doesn't exist."}}`)
+		case "A":
+			fmt.Fprint(w, `{"parse":{"wikitext":{"*":"text"}}}`)
+		default:
+			fmt.Fprint(w, `{"query":{"pages":{"1":{"title":"A","lastrevid":5}}}}`)
+		}
+	})
+	client.Cache = NewResponseCache(t.TempDir(), time.Hour)
+	ctx := context.Background()
+
+	var cached int
+	client.OnRequest = func(s RequestStats) {
+		if s.Cached {
+			cached++
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.FetchSection(ctx, "A", 0); err != nil {
+			t.Fatal(err)
+		}
+		// Error payloads aren't cached
+		if _, err := client.FetchSection(ctx, "Missing", 0); err == nil {
+			t.Fatal("no error for a missing page")
+		}
+		// Revision checks always go to the API
+		if _, err := client.FetchPageInfo(ctx, []string{"A|B"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if requests["A"] != 1 || requests["Missing"] != 2 || requests["A|B"] != 2 || cached != 1 {
+		t.Errorf("requests = %v, %d cached", requests, cached)
+	}
+
+	client.Cache.Refresh = true
+	if _, err := client.FetchSection(ctx, "A", 0); err != nil {
+		t.Fatal(err)
+	}
+	if requests["A"] != 2 {
+		t.Errorf("refresh served from cache: %v", requests)
+	}
+}
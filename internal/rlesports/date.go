@@ -0,0 +1,206 @@
+package rlesports
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* Partial dates. Liquipedia often only knows the year or month something happened, e.g. a
+membership starting "2016-04-??", so dates keep track of how precise they are. */
+
+// DatePrecision is how much of a PartialDate is known
+type DatePrecision uint8
+
+// Defined precisions, from least to most precise
+const (
+	PrecisionNone DatePrecision = iota
+	PrecisionYear
+	PrecisionMonth
+	PrecisionDay
+)
+
+// PartialDate is a date where the month and day may be unknown. The zero value is an unknown date.
+// It's written to JSON as "YYYY-MM-DD", with unknown parts as "??", or "" if unknown entirely.
+type PartialDate struct {
+	Year      int
+	Month     time.Month
+	Day       int
+	Precision DatePrecision
+}
+
+var (
+	partialDateRegex = regexp.MustCompile(`^([0-9]{4}|\?{4})(?:-([0-9]{1,2}|\?\?|[xX]{2})(?:-([0-9]{1,2}|\?\?|[xX]{2}))?)?$`)
+	// Ranges are separated by an em or en dash ("—" or "–"), or by a hyphen with spaces around it
+	// since dates themselves contain hyphens
+	dateRangeRegex = regexp.MustCompile(`\s*[–—]\s*|\s+-\s+`)
+)
+
+// NewDate returns a fully known date
+func NewDate(year int, month time.Month, day int) PartialDate {
+	return PartialDate{Year: year, Month: month, Day: day, Precision: PrecisionDay}
+}
+
+// ParsePartialDate parses "2016-04-02", "2016-04-??", "2016-??-??", "2016-04" or "2016". Unknown
+// parts may also be written as "XX". An empty string, "????-??-??", "TBD" or "TBA" is an unknown
+// date.
+func ParsePartialDate(s string) (PartialDate, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, "tbd") || strings.EqualFold(s, "tba") {
+		return PartialDate{}, nil
+	}
+
+	res := partialDateRegex.FindStringSubmatch(s)
+	if res == nil {
+		return PartialDate{}, fmt.Errorf("invalid date %q", s)
+	}
+
+	var d PartialDate
+	year, err := strconv.Atoi(res[1])
+	if err != nil {
+		return d, nil
+	}
+	d.Year, d.Precision = year, PrecisionYear
+
+	month, err := strconv.Atoi(res[2])
+	if err != nil {
+		return d, nil
+	}
+	if month < 1 || month > 12 {
+		return PartialDate{}, fmt.Errorf("invalid month in %q", s)
+	}
+	d.Month, d.Precision = time.Month(month), PrecisionMonth
+
+	day, err := strconv.Atoi(res[3])
+	if err != nil {
+		return d, nil
+	}
+	if day < 1 || day > daysIn(d.Year, d.Month) {
+		return PartialDate{}, fmt.Errorf("invalid day in %q", s)
+	}
+	d.Day, d.Precision = day, PrecisionDay
+	return d, nil
+}
+
+// ParseDateRange parses a single date or a range like "2016-04-02 - 2016-04-03". The end of a range
+// may leave out the year, e.g. "2016-04-02 - 04-03", in which case it's the first such day on or
+// after the start, so "2016-12-28 - 01-03" ends in 2017. A single date is both the start and the
+// end.
+func ParseDateRange(s string) (start, end PartialDate, err error) {
+	parts := dateRangeRegex.Split(strings.TrimSpace(s), 2)
+	if start, err = ParsePartialDate(parts[0]); err != nil || len(parts) == 1 {
+		return start, start, err
+	}
+
+	endText := strings.TrimSpace(parts[1])
+	if strings.Count(endText, "-") == 1 && start.Precision > PrecisionNone && len(endText) <= len("MM-DD") {
+		if end, err = ParsePartialDate(fmt.Sprintf("%04d-%s", start.Year, endText)); err == nil && end.Before(start) {
+			end, err = ParsePartialDate(fmt.Sprintf("%04d-%s", start.Year+1, endText))
+		}
+		return start, end, err
+	}
+	end, err = ParsePartialDate(endText)
+	return start, end, err
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// IsZero reports whether nothing is known about the date
+func (d PartialDate) IsZero() bool {
+	return d.Precision == PrecisionNone
+}
+
+// IsExact reports whether the day is known
+func (d PartialDate) IsExact() bool {
+	return d.Precision == PrecisionDay
+}
+
+func (d PartialDate) String() string {
+	switch d.Precision {
+	case PrecisionYear:
+		return fmt.Sprintf("%04d-??-??", d.Year)
+	case PrecisionMonth:
+		return fmt.Sprintf("%04d-%02d-??", d.Year, d.Month)
+	case PrecisionDay:
+		return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+	}
+	return ""
+}
+
+// Earliest is the first day the date could be. It's the zero time for an unknown date.
+func (d PartialDate) Earliest() time.Time {
+	switch d.Precision {
+	case PrecisionYear:
+		return time.Date(d.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	case PrecisionMonth:
+		return time.Date(d.Year, d.Month, 1, 0, 0, 0, 0, time.UTC)
+	case PrecisionDay:
+		return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Time{}
+}
+
+// Latest is the last day the date could be. It's the zero time for an unknown date.
+func (d PartialDate) Latest() time.Time {
+	switch d.Precision {
+	case PrecisionYear:
+		return time.Date(d.Year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	case PrecisionMonth:
+		return time.Date(d.Year, d.Month, daysIn(d.Year, d.Month), 0, 0, 0, 0, time.UTC)
+	}
+	return d.Earliest()
+}
+
+// Compare orders dates by their earliest possible day, then less precise dates first, so that
+// "2016-04-??" sorts before "2016-04-01". Unknown dates sort first. Returns -1, 0 or 1.
+func (d PartialDate) Compare(o PartialDate) int {
+	a, b := d.Earliest(), o.Earliest()
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	case d.Precision < o.Precision:
+		return -1
+	case d.Precision > o.Precision:
+		return 1
+	}
+	return 0
+}
+
+// Before reports whether d is definitely before o, i.e. every day d could be is before every day
+// o could be. Unknown dates are never definitely before or after anything.
+func (d PartialDate) Before(o PartialDate) bool {
+	return !d.IsZero() && !o.IsZero() && d.Latest().Before(o.Earliest())
+}
+
+// After reports whether d is definitely after o
+func (d PartialDate) After(o PartialDate) bool {
+	return o.Before(d)
+}
+
+// Overlaps reports whether the range [start, end] could overlap [otherStart, otherEnd]. An unknown
+// start is open into the past and an unknown end is open into the future, e.g. a membership that
+// hasn't ended yet.
+func Overlaps(start, end, otherStart, otherEnd PartialDate) bool {
+	return !end.Before(otherStart) && !otherEnd.Before(start)
+}
+
+// MarshalText writes the date the way Liquipedia does, e.g. "2016-04-??"
+func (d PartialDate) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText reads anything ParsePartialDate does
+func (d *PartialDate) UnmarshalText(text []byte) error {
+	parsed, err := ParsePartialDate(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
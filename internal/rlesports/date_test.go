@@ -0,0 +1,160 @@
+package rlesports
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePartialDate(t *testing.T) {
+	for s, want := range map[string]PartialDate{
+		"2016-04-02": NewDate(2016, time.April, 2),
+		" 2016-4-2 ": NewDate(2016, time.April, 2),
+		"2016-04-??": {Year: 2016, Month: time.April, Precision: PrecisionMonth},
+		"2016-04-XX": {Year: 2016, Month: time.April, Precision: PrecisionMonth},
+		"2016-04":    {Year: 2016, Month: time.April, Precision: PrecisionMonth},
+		"2016-??-??": {Year: 2016, Precision: PrecisionYear},
+		"2016":       {Year: 2016, Precision: PrecisionYear},
+		"????-??-??": {},
+		"":           {},
+		"TBD":        {},
+		"tba":        {},
+		"2016-02-29": NewDate(2016, time.February, 29),
+		"2016-??-02": {Year: 2016, Precision: PrecisionYear},
+		"2016-12-31": NewDate(2016, time.December, 31),
+	} {
+		got, err := ParsePartialDate(s)
+		if err != nil || got != want {
+			t.Errorf("ParsePartialDate(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+
+	for _, s := range []string{"2017-02-29", "2016-13-01", "2016-00", "16-04-02", "April 2, 2016", "TBD 2016"} {
+		if got, err := ParsePartialDate(s); err == nil {
+			t.Errorf("ParsePartialDate(%q) = %v, want an error", s, got)
+		}
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	for _, tc := range []struct {
+		s          string
+		start, end string
+	}{
+		{"2016-04-02", "2016-04-02", "2016-04-02"},
+		{"2016-04-02 - 2016-04-03", "2016-04-02", "2016-04-03"},
+		{"2016-04-02 — 2016-04-??", "2016-04-02", "2016-04-??"},
+		{"2015-07-01 – Present", "2015-07-01", ""},
+		{"2016-09-?? — 2017-02-15", "2016-09-??", "2017-02-15"},
+		// The end's year comes from the start, rolling over into the next year if need be
+		{"2016-04-02 - 04-03", "2016-04-02", "2016-04-03"},
+		{"2016-12-28 – 01-03", "2016-12-28", "2017-01-03"},
+		{"2016-12-?? – 01-??", "2016-12-??", "2017-01-??"},
+		{"2016 – 2017", "2016-??-??", "2017-??-??"},
+		{"2016-04-02 – TBD", "2016-04-02", ""},
+		{"TBD", "", ""},
+		{"", "", ""},
+	} {
+		start, end, err := ParseDateRange(tc.s)
+		if err != nil && tc.end != "" {
+			t.Errorf("ParseDateRange(%q): %v", tc.s, err)
+		}
+		if start.String() != tc.start || end.String() != tc.end {
+			t.Errorf("ParseDateRange(%q) = %v, %v, want %v, %v", tc.s, start, end, tc.start, tc.end)
+		}
+	}
+}
+
+func TestPartialDateCompare(t *testing.T) {
+	year := PartialDate{Year: 2016, Precision: PrecisionYear}
+	april := PartialDate{Year: 2016, Month: time.April, Precision: PrecisionMonth}
+	april1 := NewDate(2016, time.April, 1)
+	april2 := NewDate(2016, time.April, 2)
+	may := PartialDate{Year: 2016, Month: time.May, Precision: PrecisionMonth}
+	nextYear := PartialDate{Year: 2017, Precision: PrecisionYear}
+	var unknown PartialDate
+
+	// In order, with less precise dates before the precise dates they could be
+	ordered := []PartialDate{unknown, year, april, april1, april2, may, nextYear}
+	for i, a := range ordered {
+		for j, b := range ordered {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := a.Compare(b); got != want {
+				t.Errorf("%v.Compare(%v) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		a, b   PartialDate
+		before bool
+	}{
+		{april1, april2, true},
+		{april, may, true},
+		{april2, may, true},
+		{year, nextYear, true},
+		// Either could be first, so neither is definitely before the other
+		{april, april2, false},
+		{april2, april, false},
+		{year, april, false},
+		{april, year, false},
+		{unknown, april, false},
+		{april, unknown, false},
+		{april1, april1, false},
+	} {
+		if got := tc.a.Before(tc.b); got != tc.before {
+			t.Errorf("%v.Before(%v) = %v", tc.a, tc.b, got)
+		}
+		if got := tc.b.After(tc.a); got != tc.before {
+			t.Errorf("%v.After(%v) = %v", tc.b, tc.a, got)
+		}
+	}
+
+	for _, tc := range []struct {
+		start, end, otherStart, otherEnd PartialDate
+		overlaps                         bool
+	}{
+		{april1, april2, april2, may, true},
+		{april1, april1, april2, may, false},
+		// A month-precise end could be any day of it
+		{year, april, april2, may, true},
+		// Open-ended on either side
+		{april, unknown, nextYear, unknown, true},
+		{unknown, april1, april2, unknown, false},
+		{unknown, unknown, april, april, true},
+	} {
+		if got := Overlaps(tc.start, tc.end, tc.otherStart, tc.otherEnd); got != tc.overlaps {
+			t.Errorf("Overlaps(%v, %v, %v, %v) = %v", tc.start, tc.end, tc.otherStart, tc.otherEnd, got)
+		}
+	}
+}
+
+func TestPartialDateBounds(t *testing.T) {
+	for _, tc := range []struct {
+		d                string
+		earliest, latest string
+	}{
+		{"2016", "2016-01-01", "2016-12-31"},
+		{"2016-02", "2016-02-01", "2016-02-29"},
+		{"2017-02", "2017-02-01", "2017-02-28"},
+		{"2016-04-02", "2016-04-02", "2016-04-02"},
+	} {
+		d, err := ParsePartialDate(tc.d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d.Earliest().Format("2006-01-02"); got != tc.earliest {
+			t.Errorf("%v.Earliest() = %v, want %v", tc.d, got, tc.earliest)
+		}
+		if got := d.Latest().Format("2006-01-02"); got != tc.latest {
+			t.Errorf("%v.Latest() = %v, want %v", tc.d, got, tc.latest)
+		}
+	}
+	if !(PartialDate{}).Earliest().IsZero() || !(PartialDate{}).Latest().IsZero() {
+		t.Error("unknown date has bounds")
+	}
+}
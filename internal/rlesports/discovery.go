@@ -0,0 +1,320 @@
+package rlesports
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+/* Discovering tournaments from Liquipedia's portal and tier list pages, rather than listing them
+by hand */
+
+// Pages that list tournaments
+const (
+	RlcsPortalTitle        = "Rocket League Championship Series"
+	TournamentsPortalTitle = "Portal:Tournaments"
+)
+
+// DefaultTiers are the tiers discovered unless others are asked for
+var DefaultTiers = []string{"S-Tier", "A-Tier"}
+
+// Season path segments, e.g. "Season 5", "Season X" or "2021-22"
+var seasonSegmentRegex = regexp.MustCompile(`^(?:Season ([0-9]+|X)|([0-9]{4}(?:-[0-9]{2})?))$`)
+
+// DiscoverTournaments reads the RLCS portal and the given tiers of Portal:Tournaments into
+// skeletons, with whatever tier, season, region and dates the listings have. Tournaments listed in
+// both come out once, with the tier list's details.
+func DiscoverTournaments(ctx context.Context, client *LiquipediaClient, tiers []string) ([]Tournament, error) {
+	pages, err := client.FetchPages(ctx, []string{RlcsPortalTitle, TournamentsPortalTitle})
+	if err != nil {
+		return nil, err
+	}
+	if len(pages.Missing) > 0 {
+		return nil, fmt.Errorf("%v: %w", pages.Missing[0], ErrPageMissing)
+	}
+
+	portal, err := ParsePortal(pages.Wikitext[RlcsPortalTitle])
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", RlcsPortalTitle, err)
+	}
+	tierList, err := ParseTierList(pages.Wikitext[TournamentsPortalTitle], tiers)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", TournamentsPortalTitle, err)
+	}
+	return mergeDiscovered(portal, tierList), nil
+}
+
+// ParsePortal finds every RLCS season, region and event page linked from the portal. Finding none
+// means the portal's layout isn't what we expect, so that's an error.
+func ParsePortal(wikitext string) ([]Tournament, error) {
+	var tournaments []Tournament
+	seen := make(map[string]bool)
+	for _, link := range ParseWikitext(wikitext).Links() {
+		name := linkPage(link.Target)
+		if !strings.HasPrefix(name, RlcsPortalTitle+"/") || seen[name] {
+			continue
+		}
+		seen[name] = true
+		tournaments = append(tournaments, discoveredTournament(name))
+	}
+	if len(tournaments) == 0 {
+		return nil, malformed("no links to %v pages", RlcsPortalTitle)
+	}
+	return tournaments, nil
+}
+
+// ParseTierList reads the tournaments listed under the given tiers' headings, e.g. "==S-Tier==".
+// Entries are either templates with a |link= (or |tournament=/|page=) and dates, like
+// {{TournamentsList|link=Rocket League Championship Series/Season 5|sdate=2018-06-08|edate=2018-06-10}},
+// or plain links in a bullet list. Subheadings, such as years, stay in their tier. A tier whose
+// heading can't be found, or that lists nothing, means the page's layout isn't what we expect, so
+// that's an error rather than quietly discovering less.
+func ParseTierList(wikitext string, tiers []string) ([]Tournament, error) {
+	var tournaments []Tournament
+	seen := make(map[string]bool)
+	// Tiers whose headings were found, and how many tournaments are listed under each
+	headings := make(map[string]bool)
+	counts := make(map[string]int)
+	add := func(t Tournament, tier string) {
+		if t.Name == "" || seen[t.Name] {
+			return
+		}
+		seen[t.Name] = true
+		t.Tier = tier
+		tournaments = append(tournaments, t)
+		counts[tier]++
+	}
+
+	tier := ""
+	for _, node := range ParseWikitext(wikitext) {
+		switch n := node.(type) {
+		case Text:
+			for _, heading := range headingRegex.FindAllStringSubmatch(string(n), -1) {
+				if matched := matchTier(heading[2], tiers); matched != "" {
+					tier = matched
+					headings[tier] = true
+				} else if len(heading[1]) == 2 {
+					tier = ""
+				}
+			}
+		case *Template:
+			if tier == "" {
+				continue
+			}
+			Nodes{n}.Walk(func(t *Template) {
+				if entry, ok := parseTierListEntry(t); ok {
+					add(entry, tier)
+				}
+			})
+		case *Link:
+			if tier != "" {
+				add(discoveredTournament(linkPage(n.Target)), tier)
+			}
+		}
+	}
+
+	for _, tier := range tiers {
+		if !headings[tier] {
+			return nil, malformed("no heading for %v", tier)
+		} else if counts[tier] == 0 {
+			return nil, malformed("no tournaments listed under %v", tier)
+		}
+	}
+	return tournaments, nil
+}
+
+// matchTier returns which of tiers the heading is for, if any
+func matchTier(heading string, tiers []string) string {
+	for _, tier := range tiers {
+		if strings.Contains(strings.ToLower(heading), strings.ToLower(tier)) {
+			return tier
+		}
+	}
+	return ""
+}
+
+// parseTierListEntry reads a single tier list template, if it's one
+func parseTierListEntry(t *Template) (Tournament, bool) {
+	name := ""
+	for _, param := range []string{"link", "tournament", "page"} {
+		if value, ok := t.ParamValue(param); ok {
+			if links := value.Links(); len(links) > 0 {
+				name = linkPage(links[0].Target)
+			} else {
+				name = linkPage(value.Text())
+			}
+			break
+		}
+	}
+	if name == "" {
+		return Tournament{}, false
+	}
+
+	tournament := discoveredTournament(name)
+	tournament.Start, tournament.End, _ = ParseDateRange(t.Param("date"))
+	if sdate, err := ParsePartialDate(t.Param("sdate")); err == nil && !sdate.IsZero() {
+		tournament.Start = sdate
+	}
+	if edate, err := ParsePartialDate(t.Param("edate")); err == nil && !edate.IsZero() {
+		tournament.End = edate
+	}
+	if region := ParseRegion(t.Param("region")); region != RegionNone {
+		tournament.Regions = []Region{region}
+	}
+	return tournament, true
+}
+
+// linkPage turns a link target into a page title, dropping any anchor and leading colon
+func linkPage(target string) string {
+	if i := strings.IndexByte(target, '#'); i >= 0 {
+		target = target[:i]
+	}
+	target = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(target), ":"))
+	// Files, categories and other namespaces aren't tournaments
+	if strings.Contains(target, ":") {
+		return ""
+	}
+	return strings.ReplaceAll(target, "_", " ")
+}
+
+// discoveredTournament is a skeleton with the series, season and region worked out from the title,
+// e.g. "Rocket League Championship Series/Season 5/Europe"
+func discoveredTournament(name string) Tournament {
+	tournament := Tournament{Name: name}
+	if series, season, ok := MatchSeries(name); ok {
+		tournament.Series = series.Name()
+		tournament.Season = season
+	}
+	// Pages of unregistered series often still have a season in their path
+	segments := strings.Split(name, "/")
+	for _, segment := range segments[1:] {
+		if tournament.Season != "" {
+			break
+		}
+		if res := seasonSegmentRegex.FindStringSubmatch(segment); res != nil {
+			tournament.Season = res[1] + res[2]
+		}
+	}
+	if len(segments) > 1 {
+		if region := ParseRegion(strings.Join(segments[1:], " ")); region != RegionNone {
+			tournament.Regions = []Region{region}
+		}
+	}
+	return tournament
+}
+
+// mergeDiscovered combines tournaments found in more than one place. Later lists win for any
+// details they have.
+func mergeDiscovered(lists ...[]Tournament) []Tournament {
+	var tournaments []Tournament
+	index := make(map[string]int)
+	for _, list := range lists {
+		for _, t := range list {
+			i, ok := index[t.Name]
+			if !ok {
+				index[t.Name] = len(tournaments)
+				tournaments = append(tournaments, t)
+				continue
+			}
+			merged := &tournaments[i]
+			if t.Tier != "" {
+				merged.Tier = t.Tier
+			}
+			if t.Series != "" {
+				merged.Series = t.Series
+			}
+			if t.Season != "" {
+				merged.Season = t.Season
+			}
+			if len(t.Regions) > 0 {
+				merged.Regions = t.Regions
+			}
+			if !t.Start.IsZero() {
+				merged.Start = t.Start
+			}
+			if !t.End.IsZero() {
+				merged.End = t.End
+			}
+		}
+	}
+	return tournaments
+}
+
+// TournamentDiff is how discovered tournaments differ from stored ones
+type TournamentDiff struct {
+	// New tournaments aren't stored yet
+	New []Tournament
+	// Changed tournaments are stored with different dates than were discovered
+	Changed []Tournament
+	// Undiscovered tournaments are stored but weren't found
+	Undiscovered []Tournament
+}
+
+// DiffTournaments compares discovered tournaments against stored ones by name
+func DiffTournaments(discovered, stored []Tournament) TournamentDiff {
+	var diff TournamentDiff
+
+	byName := make(map[string]Tournament, len(stored))
+	for _, t := range stored {
+		byName[t.Name] = t
+	}
+	found := make(map[string]bool, len(discovered))
+	for _, t := range discovered {
+		found[t.Name] = true
+		s, ok := byName[t.Name]
+		if !ok {
+			diff.New = append(diff.New, t)
+		} else if (!t.Start.IsZero() && !s.Start.IsZero() && t.Start != s.Start) ||
+			(!t.End.IsZero() && !s.End.IsZero() && t.End != s.End) {
+			diff.Changed = append(diff.Changed, t)
+		}
+	}
+	for _, t := range stored {
+		if !found[t.Name] {
+			diff.Undiscovered = append(diff.Undiscovered, t)
+		}
+	}
+	return diff
+}
+
+// Summary writes out the differences, one tournament per line
+func (d TournamentDiff) Summary(w io.Writer) {
+	describe := func(t Tournament) string {
+		details := []string{}
+		if t.Series != "" {
+			details = append(details, t.Series)
+		}
+		if t.Tier != "" {
+			details = append(details, t.Tier)
+		}
+		if t.Season != "" {
+			details = append(details, "season "+t.Season)
+		}
+		for _, region := range t.Regions {
+			details = append(details, region.String())
+		}
+		if !t.Start.IsZero() || !t.End.IsZero() {
+			details = append(details, t.Start.String()+" to "+t.End.String())
+		}
+		if len(details) == 0 {
+			return t.Name
+		}
+		return fmt.Sprintf("%v (%v)", t.Name, strings.Join(details, ", "))
+	}
+
+	for _, section := range []struct {
+		label       string
+		tournaments []Tournament
+	}{
+		{"New", d.New},
+		{"Changed dates", d.Changed},
+		{"Stored but not discovered", d.Undiscovered},
+	} {
+		fmt.Fprintf(w, "%v: %d\n", section.label, len(section.tournaments))
+		for _, t := range section.tournaments {
+			fmt.Fprintf(w, "  %v\n", describe(t))
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package rlesports
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readDiscoveryPage reads a page snapshot from testdata/discovery
+func readDiscoveryPage(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", "discovery", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// TestDiscovery reads the portal snapshots and compares what's discovered against
+// testdata/discovery/discovered.golden.json
+func TestDiscovery(t *testing.T) {
+	portal, err := ParsePortal(readDiscoveryPage(t, "rlcs_portal.wikitext"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tierList, err := ParseTierList(readDiscoveryPage(t, "portal_tournaments.wikitext"), DefaultTiers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareGolden(t, filepath.Join("testdata", "discovery", "discovered.golden.json"), mergeDiscovered(portal, tierList))
+}
+
+func TestDiffTournaments(t *testing.T) {
+	discovered := []Tournament{
+		{Name: "Rocket League Championship Series/Season 5", Start: NewDate(2018, 6, 8), End: NewDate(2018, 6, 10)},
+		{Name: "Rocket League Championship Series/Season 5/Europe"},
+		{Name: "DreamHack/2018/Leipzig", Start: NewDate(2018, 1, 26)},
+	}
+	stored := []Tournament{
+		{Name: "Rocket League Championship Series/Season 5", Start: NewDate(2018, 6, 8), End: NewDate(2018, 6, 9)},
+		{Name: "Rocket League Championship Series/Season 5/Europe", Start: NewDate(2018, 4, 6)},
+		{Name: "Rocket League Championship Series/Season 4"},
+	}
+	diff := DiffTournaments(discovered, stored)
+	if len(diff.New) != 1 || len(diff.Changed) != 1 || len(diff.Undiscovered) != 1 ||
+		diff.New[0].Name != discovered[2].Name || diff.Changed[0].Name != stored[0].Name || diff.Undiscovered[0].Name != stored[2].Name {
+		t.Errorf("diff = %+v", diff)
+	}
+}
+
+func TestDiscoveryLayoutChanged(t *testing.T) {
+	tierList := readDiscoveryPage(t, "portal_tournaments.wikitext")
+	for _, tc := range []struct {
+		desc     string
+		wikitext string
+		tiers    []string
+	}{
+		{"no heading", tierList, []string{"S-Tier", "C-Tier"}},
+		{"nothing listed", "==S-Tier==\n{{Tournaments list|tier=S}}", []string{"S-Tier"}},
+	} {
+		if got, err := ParseTierList(tc.wikitext, tc.tiers); !errors.Is(err, ErrMalformedResponse) {
+			t.Errorf("%v: got %v, %v, want ErrMalformedResponse", tc.desc, got, err)
+		}
+	}
+
+	if got, err := ParsePortal("{{Infobox league|name=Rocket League Championship Series}}"); !errors.Is(err, ErrMalformedResponse) {
+		t.Errorf("portal: got %v, %v, want ErrMalformedResponse", got, err)
+	}
+}
@@ -0,0 +1,44 @@
+package rlesports
+
+import (
+	"errors"
+	"fmt"
+)
+
+/* Errors returned by the Liquipedia client */
+
+// Sentinel errors that callers can check against with errors.Is
+var (
+	ErrPageMissing       = errors.New("page missing")
+	ErrRateLimited       = errors.New("rate limited")
+	ErrMalformedResponse = errors.New("malformed response")
+	ErrTransport         = errors.New("transport failure")
+	ErrResponseTooLarge  = errors.New("response too large")
+)
+
+// APIError is the error payload Liquipedia returns in place of a result, e.g.
+// {"error":{"code":"missingtitle","info":"The page you specified doesn't exist."}}
+type APIError struct {
+	Code string `json:"code"`
+	Info string `json:"info"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("liquipedia: %s: %s", e.Code, e.Info)
+}
+
+// Is maps known API error codes onto the sentinel errors above
+func (e *APIError) Is(target error) bool {
+	switch e.Code {
+	case "missingtitle", "nosuchsection", "nosuchrevid", "invalidtitle":
+		return target == ErrPageMissing
+	case "ratelimited":
+		return target == ErrRateLimited
+	}
+	return false
+}
+
+// malformed wraps a decoding problem as ErrMalformedResponse
+func malformed(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrMalformedResponse, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,159 @@
+package rlesports
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Run e.g. `go test ./internal/rlesports -fuzz FuzzParsePlayer` to fuzz a single parser. Without
+// -fuzz, the seeds below and anything in testdata/fuzz run as regular tests.
+
+// addSnapshotSeeds seeds f with the golden corpus snapshots, plus a few shapes that used to crash
+func addSnapshotSeeds(f *testing.F) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*", "*.wikitext"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, file := range files {
+		wikitext, err := os.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(wikitext))
+	}
+
+	f.Add("")
+	f.Add("{{Infobox player|history={{TH}}{{TH|}}{{TH|2016-04-?? —}}}}")
+	f.Add("{{TeamCard|team=|p1=[[|p1flag=")
+	f.Add(strings.Repeat("{{", 500))
+	f.Add(strings.Repeat("[[a|", 500))
+	f.Add("#REDIRECT")
+	f.Add("{{8DETeamBracket|R1D1team=A|R1D2team=|R2W1team=B|R2D1team=C|R1G1details={{BracketMatchSummary|map1win=}}}}")
+	f.Add("{{Bracket|R1M1={{Match|opponent1={{TeamOpponent|A|score=W}}|opponent2=}}}}")
+	f.Add("{{Slot|place=3-|{{Team|}}|[[]]}}{{prize pool slot|place=0|team1=TBD}}")
+	f.Add("{{GroupTableStart}}{{GroupTableSlot|{{Team|A}}|win_m=|lose_m=x|diff=−}}{{GroupTableEnd}}")
+	f.Add("==S-Tier==\n===\n==")
+}
+
+func FuzzParseWikitext(f *testing.F) {
+	addSnapshotSeeds(f)
+	f.Fuzz(func(t *testing.T, wikitext string) {
+		nodes := ParseWikitext(wikitext)
+		nodes.Text()
+		nodes.Links()
+		nodes.Walk(func(t *Template) {
+			t.Param("1")
+		})
+	})
+}
+
+func FuzzParseMatches(f *testing.F) {
+	addSnapshotSeeds(f)
+	f.Fuzz(func(t *testing.T, wikitext string) {
+		for _, match := range ParseMatches(wikitext) {
+			if match.Winner < 0 || match.Winner > 2 {
+				t.Fatalf("winner %d", match.Winner)
+			}
+		}
+	})
+}
+
+func FuzzParsePlacements(f *testing.F) {
+	addSnapshotSeeds(f)
+	f.Fuzz(func(t *testing.T, wikitext string) {
+		placings := ParsePlacements(wikitext)
+		for _, placing := range placings {
+			if placing.Placement.Start <= 0 || placing.Placement.End < placing.Placement.Start {
+				t.Fatalf("placement %+v", placing.Placement)
+			}
+		}
+		ApplyPlacements([]Team{{Name: "A"}, {Name: ""}}, placings)
+	})
+}
+
+func FuzzParseGroupTables(f *testing.F) {
+	addSnapshotSeeds(f)
+	f.Fuzz(func(t *testing.T, wikitext string) {
+		if _, err := json.Marshal(ParseGroupTables(wikitext)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func FuzzFindSection(f *testing.F) {
+	addSnapshotSeeds(f)
+	f.Fuzz(func(t *testing.T, wikitext string) {
+		if index, section := FindSection(wikitext, PlayersSectionTitle); !strings.Contains(wikitext, section) || (index < 0) != (section == "") {
+			t.Fatalf("got %d, %q", index, section)
+		}
+	})
+}
+
+func FuzzParseRenderedHistory(f *testing.F) {
+	addSnapshotSeeds(f)
+	f.Add("<tr><td>2017-06-??</td><td>—</td><td>Present</td><td>[[NRG Esports]] (Loan)</td></tr>")
+	f.Fuzz(func(t *testing.T, rendered string) {
+		ParseRenderedHistory(rendered)
+	})
+}
+
+func FuzzDiscovery(f *testing.F) {
+	addSnapshotSeeds(f)
+	f.Fuzz(func(t *testing.T, wikitext string) {
+		ParsePortal(wikitext)
+		ParseTierList(wikitext, DefaultTiers)
+	})
+}
+
+func FuzzParseTeams(f *testing.F) {
+	addSnapshotSeeds(f)
+	f.Fuzz(func(t *testing.T, wikitext string) {
+		ParseTeams(wikitext, RegionWorld)
+		ParseTeams(wikitext, RegionEurope)
+	})
+}
+
+func FuzzParseStartEndRegion(f *testing.F) {
+	addSnapshotSeeds(f)
+	f.Fuzz(func(t *testing.T, wikitext string) {
+		ParseStartEndRegion(wikitext)
+	})
+}
+
+func FuzzParsePlayer(f *testing.F) {
+	addSnapshotSeeds(f)
+	f.Fuzz(func(t *testing.T, wikitext string) {
+		player := ParsePlayer(wikitext)
+		// Whatever was parsed has to survive a round trip through storage
+		if _, err := json.Marshal(player); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func FuzzIsRedirectTo(f *testing.F) {
+	addSnapshotSeeds(f)
+	f.Add("#redirect [[Turbopolsa]]")
+	f.Add("#REDIRECT [[")
+	f.Fuzz(func(t *testing.T, wikitext string) {
+		IsRedirectTo(wikitext)
+	})
+}
+
+func FuzzFindSectionIndex(f *testing.F) {
+	// Sections as returned by action=parse&prop=sections
+	f.Add(`[{"line":"Format","anchor":"Format","index":"1"},{"line":"Participants","anchor":"Participants","index":"2"}]`)
+	f.Add(`[{"line":"Participants","anchor":"Participants","index":"T-1"}]`)
+	f.Add(`[{"line":1,"anchor":null},{"index":"3"}]`)
+	f.Add(`[{}]`)
+	f.Fuzz(func(t *testing.T, sectionsJSON string) {
+		var sections []map[string]interface{}
+		if err := json.Unmarshal([]byte(sectionsJSON), &sections); err != nil {
+			return
+		}
+		FindSectionIndex(sections, PlayersSectionTitle)
+	})
+}
@@ -0,0 +1,161 @@
+package rlesports
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+/* Player team history */
+
+// Trailing annotations on a history row's team, e.g. "iBUYPOWER (Inactive)"
+var annotationRegex = regexp.MustCompile(`\(\s*([^()]*?)\s*\)\s*$`)
+
+// membershipKinds maps history annotations, lowercased, onto membership kinds
+var membershipKinds = map[string]MembershipKind{
+	"inactive":        MembershipInactive,
+	"benched":         MembershipInactive,
+	"loan":            MembershipLoan,
+	"on loan":         MembershipLoan,
+	"substitute":      MembershipSubstitute,
+	"sub":             MembershipSubstitute,
+	"stand-in":        MembershipSubstitute,
+	"coach":           MembershipCoach,
+	"head coach":      MembershipCoach,
+	"assistant coach": MembershipCoach,
+}
+
+// teamHistoryAuto is the template that builds a player's team history from transfers
+const teamHistoryAuto = "TeamHistoryAuto"
+
+// Team history rows once rendered: a date range, with "Present" or nothing as the end for current
+// teams, followed by the team and any annotation
+var (
+	renderedRangeRegex = regexp.MustCompile(`([0-9?]{4}-[0-9?xX]{2}-[0-9?xX]{2})\s*[-–—]\s*((?:[0-9?]{4}-[0-9?xX]{2}-[0-9?xX]{2}|Present)?)`)
+	htmlTagRegex       = regexp.MustCompile(`<[^>]*>`)
+	annotationsRegex   = regexp.MustCompile(`\(\s*([^()]*?)\s*\)`)
+)
+
+// parseHistory reads team history rows from the player infobox's |history=. Pages using
+// |history={{TeamHistoryAuto}} get their history from transfers, which aren't in the wikitext, so
+// auto is set for them and FetchTeamHistory has to be used instead.
+func parseHistory(infobox *Template) (memberships []Membership, auto bool) {
+	history, _ := infobox.ParamValue("history")
+
+	memberships = []Membership{}
+	for _, row := range history.Templates("TH") {
+		if membership, ok := parseHistoryRow(row); ok {
+			memberships = append(memberships, membership)
+		}
+	}
+	return memberships, len(history.Templates(teamHistoryAuto)) > 0
+}
+
+// FetchTeamHistory gets the memberships of a player whose page uses {{TeamHistoryAuto}}, by having
+// Liquipedia expand the template on their page. Getting no memberships back is an error, since
+// the template is only used for players with transfers.
+func FetchTeamHistory(ctx context.Context, client *LiquipediaClient, page string) ([]Membership, error) {
+	rendered, err := client.ExpandTemplates(ctx, page, "{{"+teamHistoryAuto+"}}")
+	if err != nil {
+		return nil, err
+	}
+	memberships := ParseRenderedHistory(rendered)
+	if len(memberships) == 0 {
+		return nil, fmt.Errorf("%v: %w", page, malformed("no rows in expanded {{%v}}", teamHistoryAuto))
+	}
+	return memberships, nil
+}
+
+// ParseRenderedHistory reads memberships out of an expanded {{TeamHistoryAuto}}. However the rows
+// are laid out, each starts with its date range, and the first link after it is the team:
+//
+//	<tr><td>2015-07-01</td><td>—</td><td>2016-01-11</td><td>[[iBUYPOWER]] ''(Inactive)''</td></tr>
+//
+// Templates are expanded by then, so the team is taken from the link rather than a team template.
+func ParseRenderedHistory(rendered string) []Membership {
+	text := strings.ReplaceAll(htmlTagRegex.ReplaceAllString(rendered, " "), "'''", "")
+	rows := renderedRangeRegex.FindAllStringSubmatchIndex(text, -1)
+
+	memberships := []Membership{}
+	for i, row := range rows {
+		join, err := ParsePartialDate(text[row[2]:row[3]])
+		if err != nil || join.IsZero() {
+			continue
+		}
+		membership := Membership{Join: join, Kind: MembershipPlayer}
+		if leave, err := ParsePartialDate(text[row[4]:row[5]]); err == nil {
+			membership.Leave = leave
+		}
+
+		end := len(text)
+		if i+1 < len(rows) {
+			end = rows[i+1][0]
+		}
+		rest := ParseWikitext(text[row[1]:end])
+		for _, link := range rest.Links() {
+			if team := linkPage(link.Target); team != "" {
+				membership.Team = team
+				break
+			}
+		}
+		for _, annotation := range annotationsRegex.FindAllStringSubmatch(strings.ReplaceAll(rest.Text(), "''", ""), -1) {
+			if kind, ok := membershipKinds[strings.ToLower(annotation[1])]; ok {
+				membership.Kind = kind
+			}
+		}
+
+		if membership.Team != "" {
+			memberships = append(memberships, membership)
+		}
+	}
+	return memberships
+}
+
+// parseHistoryRow reads {{TH|2015-07-01 — 2016-01-11|iBUYPOWER}}, with "Present" as the end for
+// current teams. Either date may be partial, e.g. 2016-04-??. The team may be annotated, either
+// inline in italics or as a third param:
+//
+//	{{TH|2016-09-?? — 2017-02-15|[[Cloud9]] ''(Inactive)''}}
+//	{{TH|2019-03-?? — 2019-04-??|[[Rogue]]|(Substitute)}}
+//
+// Rows without a start date or team are skipped.
+func parseHistoryRow(row *Template) (Membership, bool) {
+	dates := dateRangeRegex.Split(strings.TrimSpace(row.Param("1")), 2)
+	join, err := ParsePartialDate(dates[0])
+	if err != nil || join.IsZero() {
+		return Membership{}, false
+	}
+	membership := Membership{Join: join, Kind: MembershipPlayer}
+	if len(dates) == 2 {
+		if leave, err := ParsePartialDate(dates[1]); err == nil {
+			membership.Leave = leave
+		}
+	}
+
+	team := strings.TrimSpace(strings.ReplaceAll(row.Param("2"), "''", ""))
+	for {
+		res := annotationRegex.FindStringSubmatchIndex(team)
+		if res == nil {
+			break
+		}
+		kind, ok := membershipKinds[strings.ToLower(team[res[2]:res[3]])]
+		if !ok {
+			break
+		}
+		membership.Kind = kind
+		team = strings.TrimSpace(team[:res[0]])
+	}
+	for _, name := range []string{"3", "role"} {
+		annotation := strings.Trim(strings.ReplaceAll(row.Param(name), "''", ""), "() ")
+		if kind, ok := membershipKinds[strings.ToLower(annotation)]; ok {
+			membership.Kind = kind
+		}
+	}
+
+	if team == "" {
+		return Membership{}, false
+	}
+	membership.Team = team
+	return membership, true
+}
@@ -1,10 +1,11 @@
 package rlesports
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
-	"log"
 	"os"
 )
 
@@ -21,23 +22,40 @@ const (
 
 const indent = "  "
 
-func JsonGetTournaments() (tournaments []Tournament, err error) {
-	data, err := os.ReadFile(tournamentsFilename)
+// readJSONFile unmarshals filename into v. A file that exists but can't be parsed is an error
+// naming the file, so that it's never mistaken for an empty one.
+func readJSONFile(filename string, v interface{}) error {
+	data, err := os.ReadFile(filename)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%v is corrupt: %w", filename, err)
+	}
+	return nil
+}
 
-	err = json.Unmarshal(data, &tournaments)
-	if err != nil {
+// notExist reports whether err means the file hasn't been written yet, as opposed to being
+// unreadable
+func notExist(err error) bool {
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+func JsonGetTournaments() (tournaments []Tournament, err error) {
+	if err := readJSONFile(tournamentsFilename, &tournaments); err != nil {
 		return nil, err
 	}
 	return tournaments, nil
 }
 
-func JsonSaveTournament(tournament Tournament) {
+// JsonSaveTournament adds or replaces a tournament. It fails rather than starting a new file if
+// the existing one can't be read, since that would lose every other tournament.
+func JsonSaveTournament(tournament Tournament) error {
 	tournaments, err := JsonGetTournaments()
-	if err != nil {
+	if notExist(err) {
 		tournaments = make([]Tournament, 0)
+	} else if err != nil {
+		return err
 	}
 
 	found := false
@@ -53,177 +71,191 @@ func JsonSaveTournament(tournament Tournament) {
 		tournaments = append(tournaments, tournament)
 	}
 
-	JsonSaveTournaments(tournaments)
+	return JsonSaveTournaments(tournaments)
 }
 
-func JsonSaveTournaments(tournaments []Tournament) {
-	data, err := json.MarshalIndent(tournaments, "", indent)
-	if err != nil {
-		log.Fatalf("failed to marshal into json: %v", err)
-	}
-
-	err = os.WriteFile(tournamentsFilename, data, fs.FileMode(0644))
-	if err != nil {
-		log.Fatalf("failed to write json data: %v", err)
-	}
+func JsonSaveTournaments(tournaments []Tournament) error {
+	return WriteJSONFile(tournaments, tournamentsFilename)
 }
 
 func JsonGetAllTournamentMetadata() (metadataMap map[string]TournamentLPMetadata, err error) {
-	data, err := os.ReadFile(tournamentsMetadataFileName)
-	if err != nil {
-		return nil, err
-	}
-
 	metadataMap = make(map[string]TournamentLPMetadata)
-	err = json.Unmarshal(data, &metadataMap)
-	if err != nil {
+	if err := readJSONFile(tournamentsMetadataFileName, &metadataMap); err != nil {
 		return nil, err
 	}
-
 	return metadataMap, nil
 }
 
 func JsonGetTournamentMetadata(name string) (metadata TournamentLPMetadata, err error) {
 	metadataMap, err := JsonGetAllTournamentMetadata()
-	if err != nil {
+	if notExist(err) {
+		return TournamentLPMetadata{}, fmt.Errorf("no metadata for %v: %w", name, ErrNotFound)
+	} else if err != nil {
 		return TournamentLPMetadata{}, err
 	}
 
 	if metadata, ok := metadataMap[name]; ok {
 		return metadata, nil
 	}
-	return TournamentLPMetadata{}, fmt.Errorf("no metadata found for %v", name)
+	return TournamentLPMetadata{}, fmt.Errorf("no metadata for %v: %w", name, ErrNotFound)
 }
 
-func JsonSaveTournamentMetadata(name string, metadata TournamentLPMetadata) {
+func JsonSaveTournamentMetadata(name string, metadata TournamentLPMetadata) error {
 	metadataMap, err := JsonGetAllTournamentMetadata()
-	if err != nil {
+	if notExist(err) {
 		metadataMap = make(map[string]TournamentLPMetadata)
+	} else if err != nil {
+		return err
 	}
 
 	metadataMap[name] = metadata
 
-	JsonSaveAllTournamentMetadata(metadataMap)
+	return JsonSaveAllTournamentMetadata(metadataMap)
 }
 
-func JsonSaveAllTournamentMetadata(metadataMap map[string]TournamentLPMetadata) {
-	data, err := json.MarshalIndent(metadataMap, "", indent)
-	if err != nil {
-		log.Fatalf("failed to marshal into json: %v", err)
-	}
-
-	err = os.WriteFile(tournamentsMetadataFileName, data, fs.FileMode(0644))
-	if err != nil {
-		log.Fatalf("failed to write json data: %v", err)
-	}
+func JsonSaveAllTournamentMetadata(metadataMap map[string]TournamentLPMetadata) error {
+	return WriteJSONFile(metadataMap, tournamentsMetadataFileName)
 }
 
 func JsonGetProcessedPlayers() (processedPlayers []string, err error) {
-	data, err := os.ReadFile(processedPlayersFilename)
-	if err != nil {
+	if err := readJSONFile(processedPlayersFilename, &processedPlayers); err != nil {
 		return nil, err
 	}
-
-	err = json.Unmarshal(data, &processedPlayers)
-	if err != nil {
-		return nil, err
-	}
-
 	return processedPlayers, nil
 }
 
 func JsonGetPlayerNames() (playerNames map[string]string, err error) {
-	data, err := os.ReadFile(playerNamesFilename)
-	if err != nil {
+	if err := readJSONFile(playerNamesFilename, &playerNames); err != nil {
 		return nil, err
 	}
-
-	err = json.Unmarshal(data, &playerNames)
-	if err != nil {
-		return nil, err
-	}
-
 	return playerNames, nil
 }
 
-func JsonSaveProcessedPlayers(processedPlayers []string) {
-	data, err := json.MarshalIndent(processedPlayers, "", indent)
-	if err != nil {
-		log.Fatalf("failed to marshal into json: %v", err)
-	}
-
-	err = os.WriteFile(processedPlayersFilename, data, fs.FileMode(0644))
-	if err != nil {
-		log.Fatalf("failed to write json data: %v", err)
-	}
+func JsonSaveProcessedPlayers(processedPlayers []string) error {
+	return WriteJSONFile(processedPlayers, processedPlayersFilename)
 }
 
-func JsonSavePlayerNames(playerNames map[string]string) {
-	data, err := json.MarshalIndent(playerNames, "", indent)
-	if err != nil {
-		log.Fatalf("failed to marshal into json: %v", err)
-	}
-
-	err = os.WriteFile(playerNamesFilename, data, fs.FileMode(0644))
-	if err != nil {
-		log.Fatalf("failed to write json data: %v", err)
-	}
+func JsonSavePlayerNames(playerNames map[string]string) error {
+	return WriteJSONFile(playerNames, playerNamesFilename)
 }
 
+// JsonStorage keeps everything in JSON files. Files that haven't been written yet read as empty;
+// files that can't be parsed are errors. Saves are quick local writes, so they go ahead even if ctx
+// is cancelled, which lets a cancelled run keep its progress.
 type JsonStorage struct {
 }
 
-func (js JsonStorage) GetTournament(tournament *Tournament, metadata *TournamentLPMetadata) error {
+func (js JsonStorage) GetTournament(ctx context.Context, tournament *Tournament, metadata *TournamentLPMetadata) error {
 	// Tournament
-	tournaments, err := JsonGetTournaments()
+	tournaments, err := js.GetAllTournaments(ctx)
 	if err != nil {
 		return err
 	}
 
 	// Find the requested tournament
+	found := false
 	for _, t := range tournaments {
 		if t.Name == tournament.Name {
 			tournament.Start = t.Start
 			tournament.End = t.End
 			tournament.Teams = t.Teams
+			tournament.Matches = t.Matches
+			tournament.Groups = t.Groups
+			if len(tournament.Regions) == 0 {
+				tournament.Regions = t.Regions
+			}
+			if tournament.Tier == "" {
+				tournament.Tier = t.Tier
+			}
+			if tournament.Series == "" {
+				tournament.Series = t.Series
+			}
+			found = true
 			break
 		}
 	}
+	if !found {
+		return fmt.Errorf("%v: %w", tournament.Name, ErrNotFound)
+	}
 
 	// Metadata
 	*metadata, err = JsonGetTournamentMetadata(tournament.Name)
-	if err != nil {
+	return err
+}
+
+func (js JsonStorage) SaveTournament(_ context.Context, tournament Tournament, metadata TournamentLPMetadata) error {
+	if err := JsonSaveTournament(tournament); err != nil {
 		return err
 	}
+	return JsonSaveTournamentMetadata(tournament.Name, metadata)
+}
 
-	return nil
+func (js JsonStorage) GetAllTournaments(ctx context.Context) ([]Tournament, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	tournaments, err := JsonGetTournaments()
+	if notExist(err) {
+		return make([]Tournament, 0), nil
+	}
+	return tournaments, err
 }
 
-func (js JsonStorage) SaveTournament(tournament Tournament, metadata TournamentLPMetadata) {
-	JsonSaveTournament(tournament)
-	JsonSaveTournamentMetadata(tournament.Name, metadata)
+func (js JsonStorage) GetTournamentsByRegion(ctx context.Context, region Region) ([]Tournament, error) {
+	all, err := js.GetAllTournaments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tournaments []Tournament
+	for _, t := range all {
+		if t.InRegion(region) {
+			tournaments = append(tournaments, t)
+		}
+	}
+	return tournaments, nil
 }
 
-func (js JsonStorage) GetAllTournaments() (tournaments []Tournament) {
-	tournaments, err := JsonGetTournaments()
+func (js JsonStorage) GetStandings(ctx context.Context, name string) ([]GroupTable, error) {
+	tournaments, err := js.GetAllTournaments(ctx)
 	if err != nil {
-		return make([]Tournament, 0)
+		return nil, err
 	}
-	return tournaments
+
+	for _, t := range tournaments {
+		if t.Name == name {
+			return t.Groups, nil
+		}
+	}
+	return nil, fmt.Errorf("%v: %w", name, ErrNotFound)
 }
 
-func (js JsonStorage) GetProcessedPlayers() ([]string, error) {
-	return JsonGetProcessedPlayers()
+func (js JsonStorage) GetProcessedPlayers(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	processedPlayers, err := JsonGetProcessedPlayers()
+	if notExist(err) {
+		return make([]string, 0), nil
+	}
+	return processedPlayers, err
 }
 
-func (js JsonStorage) GetPlayerNames() (map[string]string, error) {
-	return JsonGetPlayerNames()
+func (js JsonStorage) GetPlayerNames(ctx context.Context) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	playerNames, err := JsonGetPlayerNames()
+	if notExist(err) || (err == nil && playerNames == nil) {
+		return make(map[string]string), nil
+	}
+	return playerNames, err
 }
 
-func (js JsonStorage) SaveProcessedPlayers(processedPlayers []string) {
-	JsonSaveProcessedPlayers(processedPlayers)
+func (js JsonStorage) SaveProcessedPlayers(_ context.Context, processedPlayers []string) error {
+	return JsonSaveProcessedPlayers(processedPlayers)
 }
 
-func (js JsonStorage) SavePlayerNames(playerNames map[string]string) {
-	JsonSavePlayerNames(playerNames)
+func (js JsonStorage) SavePlayerNames(_ context.Context, playerNames map[string]string) error {
+	return JsonSavePlayerNames(playerNames)
 }
@@ -0,0 +1,90 @@
+package rlesports
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// inTempDir runs the test from an empty directory, since JsonStorage uses repo-relative paths
+func inTempDir(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for _, sub := range []string{"src/data", "cache"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestJsonStorageMissingFiles(t *testing.T) {
+	inTempDir(t)
+	ctx := context.Background()
+	var storage JsonStorage
+
+	tournaments, err := storage.GetAllTournaments(ctx)
+	if err != nil || len(tournaments) != 0 {
+		t.Errorf("GetAllTournaments = %v, %v", tournaments, err)
+	}
+	names, err := storage.GetPlayerNames(ctx)
+	if err != nil || names == nil {
+		t.Errorf("GetPlayerNames = %v, %v", names, err)
+	}
+
+	tournament := Tournament{Name: "A"}
+	var metadata TournamentLPMetadata
+	if err := storage.GetTournament(ctx, &tournament, &metadata); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetTournament = %v, want ErrNotFound", err)
+	}
+	if err := storage.SaveTournament(ctx, Tournament{Name: "A", Season: "1"}, TournamentLPMetadata{RevisionID: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.GetTournament(ctx, &tournament, &metadata); err != nil || metadata.RevisionID != 5 {
+		t.Errorf("GetTournament after save = %+v, %v", metadata, err)
+	}
+}
+
+func TestJsonStorageCorruptFile(t *testing.T) {
+	inTempDir(t)
+	ctx := context.Background()
+	var storage JsonStorage
+
+	corrupt := []byte(`[{"name": "A"`)
+	if err := os.WriteFile(tournamentsFilename, corrupt, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if tournaments, err := storage.GetAllTournaments(ctx); err == nil {
+		t.Errorf("GetAllTournaments = %v, want an error", tournaments)
+	}
+	tournament := Tournament{Name: "A"}
+	var metadata TournamentLPMetadata
+	if err := storage.GetTournament(ctx, &tournament, &metadata); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("GetTournament = %v, want a storage error", err)
+	}
+
+	// Saving over a file that can't be read would lose everything in it
+	if err := storage.SaveTournament(ctx, Tournament{Name: "B"}, TournamentLPMetadata{}); err == nil {
+		t.Error("SaveTournament succeeded")
+	}
+	if data, _ := os.ReadFile(tournamentsFilename); string(data) != string(corrupt) {
+		t.Errorf("file was overwritten with %s", data)
+	}
+
+	// Updates stop rather than trying every tournament against the same broken file
+	err := updateTournament(ctx, nil, storage, Tournament{Name: "A"}, PageInfo{}, false)
+	var storageErr *StorageError
+	if !errors.As(err, &storageErr) {
+		t.Errorf("updateTournament = %v, want a StorageError", err)
+	}
+}
@@ -0,0 +1,134 @@
+package rlesports
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// Run `go test ./internal/rlesports -run Live -live` to check what's in the repo against
+// liquipedia.net itself. These tests are skipped otherwise, since they need the network and are
+// rate limited like any other client.
+var live = flag.Bool("live", false, "check series and snapshots against liquipedia.net")
+
+// newLiveClient is a client for the real Liquipedia API, or skips the test without -live
+func newLiveClient(t *testing.T) *LiquipediaClient {
+	t.Helper()
+	if !*live {
+		t.Skip("run with -live to check against liquipedia.net")
+	}
+	return NewLiquipediaClient()
+}
+
+// TestLiveSeriesPages checks that every page the series config lists exists
+func TestLiveSeriesPages(t *testing.T) {
+	client := newLiveClient(t)
+	series, err := LoadSeriesConfig(filepath.Join("..", "..", seriesFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var titles []string
+	for _, s := range series {
+		skeletons, err := s.Skeletons(len(s.Events))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tournament := range skeletons {
+			titles = append(titles, tournament.Name)
+		}
+	}
+	infos, err := client.FetchPageInfo(context.Background(), titles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range titles {
+		if info, ok := infos[title]; !ok || info.Missing {
+			t.Errorf("%v: no such page", title)
+		}
+	}
+}
+
+// TestLiveDiscovery reads the real portals, which fails if their layout isn't what the parsers
+// expect
+func TestLiveDiscovery(t *testing.T) {
+	client := newLiveClient(t)
+	discovered, err := DiscoverTournaments(context.Background(), client, DefaultTiers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dated := 0
+	for _, tournament := range discovered {
+		if tournament.Tier != "" && !tournament.Start.IsZero() {
+			dated++
+		}
+	}
+	if dated == 0 {
+		t.Errorf("none of %d tournaments came with a tier and dates", len(discovered))
+	}
+}
+
+// snapshotSource is the Liquipedia page a testdata snapshot is a copy of, and the revision it was
+// captured at. A zero revision means it has never been captured, i.e. it was written by hand.
+type snapshotSource struct {
+	Page       string `json:"page"`
+	RevisionID int64  `json:"revid,omitempty"`
+}
+
+// snapshotsFile lists the source of each snapshot, keyed by its path under testdata
+var snapshotsFile = filepath.Join("testdata", "snapshots.json")
+
+// TestLiveRecapture replaces every snapshot listed in testdata/snapshots.json with the current
+// revision of its page, and records the revision. Run `go test ./internal/rlesports -update`
+// afterwards to regenerate the goldens, and review the diff.
+func TestLiveRecapture(t *testing.T) {
+	client := newLiveClient(t)
+	ctx := context.Background()
+
+	data, err := os.ReadFile(snapshotsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sources map[string]snapshotSource
+	if err := json.Unmarshal(data, &sources); err != nil {
+		t.Fatal(err)
+	}
+
+	var files, pages []string
+	for file, source := range sources {
+		files = append(files, file)
+		pages = append(pages, source.Page)
+	}
+	sort.Strings(files)
+	infos, err := client.FetchPageInfo(ctx, pages)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		source := sources[file]
+		info, ok := infos[source.Page]
+		if !ok || info.Missing || info.RevisionID == 0 {
+			t.Errorf("%v: no page %v", file, source.Page)
+			continue
+		}
+		wikitext, err := client.FetchRevision(ctx, info.RevisionID)
+		if err != nil {
+			t.Errorf("%v: %v", file, err)
+			continue
+		}
+		if err := os.WriteFile(filepath.Join("testdata", filepath.FromSlash(file)), []byte(wikitext), 0644); err != nil {
+			t.Fatal(err)
+		}
+		source.RevisionID = info.RevisionID
+		sources[file] = source
+	}
+
+	if err := WriteJSONFile(sources, snapshotsFile); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,429 @@
+package rlesports
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/* Match result parsing from brackets and match lists */
+
+var (
+	// Bracket params are named like R1M1 (new brackets) or R1D1team (legacy brackets)
+	bracketMatchRegex  = regexp.MustCompile(`^R([0-9]+)M([0-9]+)$`)
+	bracketHeaderRegex = regexp.MustCompile(`^R([0-9]+)M[0-9]+header$`)
+	legacySlotRegex    = regexp.MustCompile(`^R([0-9]+)([A-Z])([0-9]+)team$`)
+	legacyDetailsRegex = regexp.MustCompile(`^R([0-9]+)G([0-9]+)details$`)
+	matchlistRegex     = regexp.MustCompile(`^M([0-9]+)$`)
+	headingRegex       = regexp.MustCompile(`(?m)^(={2,6})\s*(.*?)\s*={2,6}\s*$`)
+	scoreRegex         = regexp.MustCompile(`^\s*([0-9]+)\s*[-–—:]\s*([0-9]+)\s*$`)
+)
+
+// ParseMatches finds every match on a tournament page. It understands the current {{Bracket}} and
+// {{Matchlist}} templates holding {{Match}}es, standalone {{MatchMaps}}, and legacy *Bracket
+// templates whose details are {{BracketMatchSummary}}s. Byes aren't matches, so they're left out.
+func ParseMatches(wikitext string) []Match {
+	matches := []Match{}
+
+	heading := ""
+	for _, node := range ParseWikitext(wikitext) {
+		switch n := node.(type) {
+		case Text:
+			// Matches outside of brackets get the heading they're under as their round
+			if found := headingRegex.FindAllStringSubmatch(string(n), -1); len(found) > 0 {
+				heading = found[len(found)-1][2]
+			}
+		case *Template:
+			for _, match := range findMatches(n, heading) {
+				if !isBye(match.Teams[0]) && !isBye(match.Teams[1]) {
+					matches = append(matches, match)
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+// isBye reports whether an opponent is a bye, e.g. {{TeamOpponent|BYE}}
+func isBye(team string) bool {
+	return strings.EqualFold(team, "bye")
+}
+
+// forfeitWinner decides the winner of a forfeited match, where the team that advanced is scored W
+// and the team that didn't FF (forfeit), DQ (disqualified) or L
+func forfeitWinner(scores [2]string) int {
+	for i, score := range scores {
+		switch strings.ToUpper(strings.TrimSpace(score)) {
+		case "W":
+			return i + 1
+		case "FF", "DQ", "L":
+			return 2 - i
+		}
+	}
+	return 0
+}
+
+// findMatches gets all matches from template or anywhere nested inside of it
+func findMatches(template *Template, heading string) []Match {
+	switch {
+	case template.Is("Bracket"):
+		return parseBracket(template)
+	case template.Is("Matchlist"):
+		return parseMatchlist(template, heading)
+	case template.Is("Match"):
+		return []Match{parseMatch(template, heading)}
+	case template.Is("MatchMaps"):
+		return []Match{parseMatchMaps(template, heading)}
+	case strings.HasSuffix(normalizeName(template.Name), "bracket"):
+		return parseLegacyBracket(template)
+	}
+
+	var matches []Match
+	for _, param := range template.Params {
+		for _, node := range param.Value {
+			if nested, ok := node.(*Template); ok {
+				matches = append(matches, findMatches(nested, heading)...)
+			}
+		}
+	}
+	return matches
+}
+
+// bracketRound names a round by its header if it has one
+func bracketRound(headers map[int]string, round int) string {
+	if header, ok := headers[round]; ok {
+		return header
+	}
+	return fmt.Sprintf("Round %d", round)
+}
+
+// parseBracket reads {{Bracket|R1M1={{Match|...}}|R1M1header=Quarterfinals|...}}
+func parseBracket(bracket *Template) []Match {
+	headers := make(map[int]string)
+	for _, param := range bracket.Params {
+		if res := bracketHeaderRegex.FindStringSubmatch(param.Name); res != nil {
+			round, _ := strconv.Atoi(res[1])
+			headers[round] = strings.TrimSpace(param.Value.Text())
+		}
+	}
+
+	var matches []Match
+	for _, param := range bracket.Params {
+		res := bracketMatchRegex.FindStringSubmatch(param.Name)
+		if res == nil {
+			continue
+		}
+		round, _ := strconv.Atoi(res[1])
+		for _, match := range param.Value.Templates("Match") {
+			matches = append(matches, parseMatch(match, bracketRound(headers, round)))
+		}
+	}
+	return matches
+}
+
+// parseMatchlist reads {{Matchlist|title=Group A|M1={{Match|...}}|M1header=Opening Matches|...}}
+func parseMatchlist(matchlist *Template, heading string) []Match {
+	round := heading
+	if title := matchlist.Param("title"); title != "" {
+		round = title
+	}
+
+	var matches []Match
+	for _, param := range matchlist.Params {
+		if !matchlistRegex.MatchString(param.Name) {
+			continue
+		}
+		if header := matchlist.Param(param.Name + "header"); header != "" {
+			round = header
+		}
+		for _, match := range param.Value.Templates("Match") {
+			matches = append(matches, parseMatch(match, round))
+		}
+	}
+	return matches
+}
+
+// isTeamTemplate reports whether the template stands for a team, e.g. {{TeamOpponent|Cloud9}},
+// {{Team|Cloud9}} or {{TeamShort|C9}}
+func isTeamTemplate(t *Template) bool {
+	name := normalizeName(t.Name)
+	return strings.HasPrefix(name, "team") || strings.HasSuffix(name, "opponent")
+}
+
+// parseOpponent reads {{TeamOpponent|Cloud9|score=4}} (or any other team template). The score is
+// left as given since forfeits are scored with letters.
+func parseOpponent(value Nodes) (name string, score string) {
+	for _, node := range value {
+		opponent, ok := node.(*Template)
+		if !ok || !isTeamTemplate(opponent) {
+			continue
+		}
+		name = opponent.Param("1")
+		if name == "" {
+			name = opponent.Param("template")
+		}
+		if name == "" {
+			name = opponent.Param("name")
+		}
+		return name, opponent.Param("score")
+	}
+	// Plain team names are fine too
+	return strings.TrimSpace(value.Text()), ""
+}
+
+// parseWinner reads a winner param, which is 1 or 2 when decided
+func parseWinner(s string) int {
+	if winner, err := strconv.Atoi(s); err == nil && (winner == 1 || winner == 2) {
+		return winner
+	}
+	return 0
+}
+
+// parseMatch reads {{Match|opponent1=...|opponent2=...|bestof=7|date=...|map1={{Map|...}}|...}}
+func parseMatch(template *Template, round string) Match {
+	match := Match{Round: round, Date: template.Param("date")}
+	match.BestOf, _ = strconv.Atoi(template.Param("bestof"))
+
+	var scores [2]string
+	var hasScore [2]bool
+	for i := 0; i < 2; i++ {
+		value, _ := template.ParamValue(fmt.Sprintf("opponent%d", i+1))
+		match.Teams[i], scores[i] = parseOpponent(value)
+		var err error
+		match.Score[i], err = strconv.Atoi(scores[i])
+		hasScore[i] = err == nil
+	}
+
+	for n := 1; ; n++ {
+		value, ok := template.ParamValue(fmt.Sprintf("map%d", n))
+		if !ok {
+			// Games are sometimes called game1, game2, ...
+			if value, ok = template.ParamValue(fmt.Sprintf("game%d", n)); !ok {
+				break
+			}
+		}
+		for _, m := range value.Templates("Map", "Game") {
+			game := Game{Map: m.Param("map"), Winner: parseWinner(m.Param("winner"))}
+			game.Score[0], _ = strconv.Atoi(m.Param("score1"))
+			game.Score[1], _ = strconv.Atoi(m.Param("score2"))
+			game.Overtime = m.Param("ot") != "" && m.Param("ot") != "false"
+			if game.Winner == 0 && game.Score[0] != game.Score[1] {
+				game.Winner = gameWinner(game.Score)
+			}
+			if game.Winner != 0 {
+				match.Games = append(match.Games, game)
+			}
+		}
+	}
+
+	// If the series score isn't given, count the games
+	if !hasScore[0] && !hasScore[1] {
+		match.Score = countGames(match.Games)
+	}
+	match.Winner = parseWinner(template.Param("winner"))
+	if match.Winner == 0 {
+		match.Winner = forfeitWinner(scores)
+	}
+	if match.Winner == 0 {
+		match.Winner = seriesWinner(match.Score, match.BestOf)
+	}
+	return match
+}
+
+// parseMatchMaps reads {{MatchMaps|team1=|team2=|games1=|games2=|winner=|details={{BracketMatchSummary|...}}}}
+func parseMatchMaps(template *Template, round string) Match {
+	match := Match{
+		Round: round,
+		Date:  template.Param("date"),
+		Teams: [2]string{template.Param("team1"), template.Param("team2")},
+	}
+	match.Score[0], _ = strconv.Atoi(template.Param("games1"))
+	match.Score[1], _ = strconv.Atoi(template.Param("games2"))
+
+	details, _ := template.ParamValue("details")
+	for _, summary := range details.Templates("BracketMatchSummary") {
+		match.BestOf, match.Games = parseMatchSummary(summary)
+		if match.Date == "" {
+			match.Date = summary.Param("date")
+		}
+	}
+
+	match.Winner = parseWinner(template.Param("winner"))
+	if match.Winner == 0 {
+		match.Winner = seriesWinner(match.Score, match.BestOf)
+	}
+	return match
+}
+
+// parseMatchSummary reads the games out of a legacy {{BracketMatchSummary}}, where game N is given
+// as |mapN=DFH Stadium |mapNscore=3-1 |mapNwin=1 |mapNot=true
+func parseMatchSummary(summary *Template) (bestOf int, games []Game) {
+	bestOf, _ = strconv.Atoi(summary.Param("bestof"))
+	for n := 1; ; n++ {
+		prefix := fmt.Sprintf("map%d", n)
+		if !summary.HasParam(prefix) && !summary.HasParam(prefix+"win") {
+			break
+		}
+		game := Game{Map: summary.Param(prefix), Winner: parseWinner(summary.Param(prefix + "win"))}
+		if res := scoreRegex.FindStringSubmatch(summary.Param(prefix + "score")); res != nil {
+			game.Score[0], _ = strconv.Atoi(res[1])
+			game.Score[1], _ = strconv.Atoi(res[2])
+		}
+		game.Overtime = summary.Param(prefix+"ot") != "" && summary.Param(prefix+"ot") != "false"
+		if game.Winner == 0 && game.Score[0] != game.Score[1] {
+			game.Winner = gameWinner(game.Score)
+		}
+		if game.Winner != 0 {
+			games = append(games, game)
+		}
+	}
+	return bestOf, games
+}
+
+// legacySlot is one side of a match in a legacy bracket
+type legacySlot struct {
+	round, index int
+	// group is the slot's letter's place in its round, e.g. W (upper bracket) before D (lower
+	// bracket) if that's the order they're given in
+	group  int
+	team   string
+	score  string
+	winner bool
+}
+
+// parseLegacyBracket reads legacy brackets such as {{8SETeamBracket}} or {{16DETeamBracket}}, where
+// each side of a match is given as |R1D1team=Cloud9 |R1D1score=4 |R1D1win=1. Consecutive slots with
+// the same letter face each other, e.g. R2W1 and R2W2 in the upper bracket and R2D1 and R2D2 in the
+// lower. |R1G1details={{BracketMatchSummary|...}} is the round's first match, counting through the
+// round's letters in the order they're given.
+func parseLegacyBracket(bracket *Template) []Match {
+	var slots []legacySlot
+	groups := make(map[int]map[string]int)
+	for _, param := range bracket.Params {
+		res := legacySlotRegex.FindStringSubmatch(param.Name)
+		if res == nil {
+			continue
+		}
+		prefix := strings.TrimSuffix(param.Name, "team")
+		slot := legacySlot{team: strings.TrimSpace(param.Value.Text())}
+		slot.round, _ = strconv.Atoi(res[1])
+		slot.index, _ = strconv.Atoi(res[3])
+		if groups[slot.round] == nil {
+			groups[slot.round] = make(map[string]int)
+		}
+		group, ok := groups[slot.round][res[2]]
+		if !ok {
+			group = len(groups[slot.round])
+			groups[slot.round][res[2]] = group
+		}
+		slot.group = group
+		slot.score = bracket.Param(prefix + "score")
+		slot.winner = bracket.Param(prefix+"win") != ""
+		slots = append(slots, slot)
+	}
+	sort.SliceStable(slots, func(i, j int) bool {
+		if slots[i].round != slots[j].round {
+			return slots[i].round < slots[j].round
+		}
+		if slots[i].group != slots[j].group {
+			return slots[i].group < slots[j].group
+		}
+		return slots[i].index < slots[j].index
+	})
+
+	// Match details, by round, in order
+	details := make(map[int][]*Template)
+	for _, param := range bracket.Params {
+		if res := legacyDetailsRegex.FindStringSubmatch(param.Name); res != nil {
+			round, _ := strconv.Atoi(res[1])
+			details[round] = append(details[round], param.Value.Templates("BracketMatchSummary")...)
+		}
+	}
+
+	var matches []Match
+	inRound := make(map[int]int)
+	for i := 0; i+1 < len(slots); i += 2 {
+		a, b := slots[i], slots[i+1]
+		if a.round != b.round || a.group != b.group {
+			// Odd number of slots in a round or group; realign on the next one
+			i--
+			continue
+		}
+		if a.team == "" && b.team == "" {
+			continue
+		}
+		// Nobody can come through to an empty first round slot, so the other team has a bye
+		if a.round == 1 && (a.team == "" || b.team == "") {
+			inRound[a.round]++
+			continue
+		}
+
+		round := bracket.Param(fmt.Sprintf("R%d", a.round))
+		if round == "" {
+			round = fmt.Sprintf("Round %d", a.round)
+		}
+		match := Match{Round: round, Teams: [2]string{a.team, b.team}}
+		var errA, errB error
+		match.Score[0], errA = strconv.Atoi(a.score)
+		match.Score[1], errB = strconv.Atoi(b.score)
+		if a.winner {
+			match.Winner = 1
+		} else if b.winner {
+			match.Winner = 2
+		} else {
+			match.Winner = forfeitWinner([2]string{a.score, b.score})
+		}
+
+		if summaries := details[a.round]; inRound[a.round] < len(summaries) {
+			summary := summaries[inRound[a.round]]
+			match.BestOf, match.Games = parseMatchSummary(summary)
+			match.Date = summary.Param("date")
+		}
+		inRound[a.round]++
+
+		if errA != nil && errB != nil {
+			match.Score = countGames(match.Games)
+		}
+		if match.Winner == 0 {
+			match.Winner = seriesWinner(match.Score, match.BestOf)
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+func gameWinner(score [2]int) int {
+	if score[0] > score[1] {
+		return 1
+	} else if score[1] > score[0] {
+		return 2
+	}
+	return 0
+}
+
+func countGames(games []Game) (score [2]int) {
+	for _, game := range games {
+		if game.Winner != 0 {
+			score[game.Winner-1]++
+		}
+	}
+	return score
+}
+
+// seriesWinner decides the winner from the series score, which is only certain if we know how
+// many games it was played to
+func seriesWinner(score [2]int, bestOf int) int {
+	if bestOf <= 0 {
+		return 0
+	}
+	needed := bestOf/2 + 1
+	if score[0] >= needed {
+		return 1
+	} else if score[1] >= needed {
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+package rlesports
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+/* Per-request metrics */
+
+// RequestStats describes a single API request
+type RequestStats struct {
+	Action string
+	// Target is the page (or pages, or revision) the request was about
+	Target string
+	// Status is the HTTP status code, or zero if there was no response
+	Status   int
+	Duration time.Duration
+	// WireBytes is the size of the body as transferred, Bytes its size once decompressed
+	WireBytes  int64
+	Bytes      int64
+	Compressed bool
+	Cached     bool
+}
+
+// requestTarget picks out whatever the request is about from its parameters
+func requestTarget(opts url.Values) string {
+	for _, key := range []string{"page", "oldid", "titles"} {
+		if target := opts.Get(key); target != "" {
+			return target
+		}
+	}
+	return ""
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// RequestMetrics collects RequestStats, e.g. as a LiquipediaClient's OnRequest
+type RequestMetrics struct {
+	mu    sync.Mutex
+	stats []RequestStats
+}
+
+// Record adds a request's stats
+func (m *RequestMetrics) Record(stats RequestStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats = append(m.stats, stats)
+}
+
+// Summary writes totals followed by the top slowest requests to w
+func (m *RequestMetrics) Summary(w io.Writer, top int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var requests, cached int
+	var duration time.Duration
+	var wireBytes, bytes int64
+	var slowest []RequestStats
+	for _, s := range m.stats {
+		if s.Cached {
+			cached++
+			continue
+		}
+		requests++
+		duration += s.Duration
+		wireBytes += s.WireBytes
+		bytes += s.Bytes
+		slowest = append(slowest, s)
+	}
+
+	fmt.Fprintf(w, "%d requests (%d more served from cache), %v total, %d bytes transferred (%d decompressed)\n",
+		requests, cached, duration.Round(time.Millisecond), wireBytes, bytes)
+
+	sort.Slice(slowest, func(i, j int) bool { return slowest[i].Duration > slowest[j].Duration })
+	if len(slowest) > top {
+		slowest = slowest[:top]
+	}
+	for _, s := range slowest {
+		fmt.Fprintf(w, "  %8v %9d bytes  %s %s\n", s.Duration.Round(time.Millisecond), s.WireBytes, s.Action, s.Target)
+	}
+}
@@ -2,7 +2,6 @@ package rlesports
 
 import (
 	"fmt"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -11,94 +10,85 @@ import (
 /* Wikitext parsing module */
 
 var (
-	// [[Rocket_League_Championship_Series/Season_1/North_America/Qualifier_1|Qualifier #1]]
-	wikilinkRegex   = regexp.MustCompile(`\[\[.+\|(.+)\]\]`)
-	playerLineRegex = regexp.MustCompile(`[|]p[0-9]=`)
-	subLineRegex    = regexp.MustCompile(`[|]sub[0-9]=`)
-	dateRegex       = regexp.MustCompile("[\\w?]{4}-[\\w?]{2}-[\\w?]{2}")
+	playerParamRegex = regexp.MustCompile(`^p[0-9]+$`)
+	subParamRegex    = regexp.MustCompile(`^sub[0-9]+$`)
+	coachParamRegex  = regexp.MustCompile(`^c[0-9]*$`)
 )
 
+// socialParams are the player infobox params that hold social links
+var socialParams = []string{
+	"twitter", "twitch", "youtube", "facebook", "instagram", "reddit", "tiktok", "discord", "steam",
+	"stream", "website",
+}
+
 // ParseTeams parses team info. tournamentRegion is provided to set the individual region of teams
 // that don't have regions of their own.
 func ParseTeams(wikitext string, tournamentRegion Region) []Team {
-	// We are processing all of the lines per tournament
-	lines := strings.Split(wikitext, "\n")
 	// Each tournament has a set of teams
 	teams := []Team{}
-	// These are used as we parse one team at a time
-	team := Team{}
-	foundTeam := false
-
-	if tournamentRegion != RegionWorld {
-		team.Region = tournamentRegion
-	}
 
-	// Line format is:
+	// Team cards look like:
+	// {{TeamCard
 	// |team=iBUYPOWER
 	// |p1=Kronovi |p1flag=us
 	// |p2=Lachinio |p2flag=ca
 	// |p3=Gambit |p3flag=us
-	// |p4=0ver Zer0|p4flag=us
+	// |p4=0ver Zer0|p4flag=us |p4link=0ver Zer0 (player)
+	// |c=Coach |cflag=us
 	// |qualifier=[[Rocket_League_Championship_Series/Season_1/North_America/Qualifier_1|Qualifier #1]]
-	for _, line := range lines {
-		// This divides teams, so we save the team we've been collecting so far
-		if strings.HasPrefix(line, "|team") {
-			// Handle special case for the first team
-			if foundTeam && len(team.Players) >= minTeamSize {
-				teams = append(teams, team)
-				team = Team{}
-				if tournamentRegion != RegionWorld {
-					team.Region = tournamentRegion
-				}
+	// }}
+	ParseWikitext(wikitext).Walk(func(card *Template) {
+		if !card.HasParam("team") {
+			return
+		}
+
+		team := Team{Name: card.Param("team"), Image: card.Param("image")}
+		if tournamentRegion != RegionWorld {
+			team.Region = tournamentRegion
+		}
+
+		for _, param := range card.Params {
+			var role RosterRole
+			if playerParamRegex.MatchString(param.Name) {
+				role = RolePlayer
+			} else if subParamRegex.MatchString(param.Name) {
+				role = RoleSub
+			} else if coachParamRegex.MatchString(param.Name) || param.Name == "coach" {
+				role = RoleCoach
+			} else {
+				continue
 			}
-			team.Name = strings.Replace(line, "|team=", "", 1)
-			if endBar := strings.Index(team.Name, "|"); endBar >= 0 {
-				team.Name = team.Name[0:endBar]
+
+			name := strings.TrimSpace(param.Value.Text())
+			if len(name) == 0 {
+				continue
 			}
-			foundTeam = true
-			// Once we've found a team, parse at least 3 players
-		} else if foundTeam {
-			// Player line has to start as so:
-			// TODO: start with `^`? replace [|] with `|?`?
-			if res := playerLineRegex.MatchString(line); res {
-				player := strings.TrimSpace(strings.Split(strings.Split(line, "|")[1], "=")[1])
-				if len(player) > 0 {
-					team.Players = append(team.Players, player)
-				}
-			} else if res := subLineRegex.MatchString(line); res {
-				player := strings.TrimSpace(strings.Split(strings.Split(line, "|")[1], "=")[1])
-				if len(player) > 0 {
-					team.Subs = append(team.Subs, player)
-				}
-			} else {
-				if tournamentRegion == RegionWorld {
-					if strings.Contains(line, "|qualifier") {
-						pieces := strings.Split(line, "=")
-						qualifier := strings.TrimSpace(pieces[1])
-
-						// Strip away any links
-						if res := wikilinkRegex.FindStringSubmatch(qualifier); res != nil {
-							qualifier = res[1]
-						}
-
-						// TODO expand
-						if strings.Contains(qualifier, RegionNorthAmerica.String()) {
-							team.Region = RegionNorthAmerica
-						} else if strings.Contains(qualifier, RegionEurope.String()) {
-							team.Region = RegionEurope
-						} else if strings.Contains(qualifier, RegionOceania.String()) {
-							team.Region = RegionOceania
-						}
-					}
-				}
+			entry := RosterEntry{
+				Name: name,
+				Link: card.Param(param.Name + "link"),
+				Flag: card.Param(param.Name + "flag"),
+				Role: role,
+			}
+			if entry.Link == entry.Name {
+				entry.Link = ""
+			}
+			team.Roster = append(team.Roster, entry)
+			if role == RolePlayer {
+				team.Players = append(team.Players, name)
+			} else if role == RoleSub {
+				team.Subs = append(team.Subs, name)
 			}
 		}
-	}
 
-	// Fencepost for the last team
-	if len(team.Players) >= minTeamSize {
-		teams = append(teams, team)
-	}
+		if tournamentRegion == RegionWorld {
+			// Links are rendered as their display text
+			team.Region = ParseRegion(card.Param("qualifier"))
+		}
+
+		if len(team.Players) >= minTeamSize {
+			teams = append(teams, team)
+		}
+	})
 
 	return teams
 }
@@ -108,42 +98,47 @@ const (
 	typeOffline = "Offline"
 )
 
-// ParseStartEndRegion get start, end, region of tournament, or returns empty
-func ParseStartEndRegion(wikitext string) (string, string, Region) {
-	lines := strings.Split(wikitext, "\n")
-
-	inInfobox := false
+// findInfobox returns the first {{Infobox ...}} template, if there is one
+func findInfobox(nodes Nodes) *Template {
+	var infobox *Template
+	nodes.Walk(func(t *Template) {
+		if infobox == nil && t.HasPrefix("Infobox") {
+			infobox = t
+		}
+	})
+	return infobox
+}
 
-	start := ""
-	end := ""
+// ParseStartEndRegion get start, end, region of tournament, or returns empty. Single-day events
+// only have a date, which may also be a range like "2016-04-02 - 2016-04-03".
+func ParseStartEndRegion(wikitext string) (PartialDate, PartialDate, Region) {
+	var start, end PartialDate
 	tType := typeOffline
-	country := ""
-
-	for _, line := range lines {
-		if strings.HasPrefix(line, "{{Infobox") {
-			inInfobox = true
-		} else if inInfobox {
-			if strings.HasPrefix(line, "|sdate=") {
-				start = strings.Replace(line, "|sdate=", "", 1)
-			} else if strings.HasPrefix(line, "|edate=") {
-				end = strings.Replace(line, "|edate=", "", 1)
-			} else if strings.HasPrefix(line, "|type=") {
-				tType = strings.Replace(line, "|type=", "", 1)
-			} else if strings.HasPrefix(line, "|country=") {
-				country = strings.Replace(line, "|country=", "", 1)
-			}
+	regionText := ""
+
+	if infobox := findInfobox(ParseWikitext(wikitext)); infobox != nil {
+		start, end, _ = ParseDateRange(infobox.Param("date"))
+		if sdate, err := ParsePartialDate(infobox.Param("sdate")); err == nil && !sdate.IsZero() {
+			start = sdate
+		}
+		if edate, err := ParsePartialDate(infobox.Param("edate")); err == nil && !edate.IsZero() {
+			end = edate
+		}
+		if infobox.HasParam("type") {
+			tType = infobox.Param("type")
+		}
+		// Online events name their region in |region=, or in |country= on older pages
+		regionText = infobox.Param("region")
+		if regionText == "" {
+			regionText = infobox.Param("country")
 		}
 	}
 
 	region := RegionNone
 	if tType == typeOffline {
 		region = RegionWorld
-	} else if country == RegionNorthAmerica.String() {
-		region = RegionNorthAmerica
-	} else if country == RegionEurope.String() {
-		region = RegionEurope
-	} else if country == RegionOceania.String() {
-		region = RegionOceania
+	} else {
+		region = ParseRegion(regionText)
 	}
 
 	return start, end, region
@@ -151,55 +146,76 @@ func ParseStartEndRegion(wikitext string) (string, string, Region) {
 
 // ParsePlayer parses player from wikitext
 func ParsePlayer(wikitext string) Player {
-	lines := strings.Split(wikitext, "\n")
-
 	player := Player{Memberships: []Membership{}}
 
-	inInfobox := false
-	inHistory := false
-
-	for _, line := range lines {
-		if strings.HasPrefix(line, "{{Infobox") {
-			inInfobox = true
-		} else if inInfobox {
-			if strings.HasPrefix(line, "|id=") {
-				player.Name = strings.TrimSpace(strings.Replace(line, "|id=", "", 1))
-			} else if strings.HasPrefix(line, "|ids=") {
-				alternateIDs := strings.Split(strings.Replace(line, "|ids=", "", 1), ", ")
-				for _, id := range alternateIDs {
-					if id != "" {
-						player.AlternateIDs = append(player.AlternateIDs, id)
-					}
-				}
-			}
+	nodes := ParseWikitext(wikitext)
+	infobox := findInfobox(nodes)
+	if infobox == nil {
+		return player
+	}
+
+	player.Name = infobox.Param("id")
+	for _, id := range strings.Split(infobox.Param("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			player.AlternateIDs = append(player.AlternateIDs, id)
 		}
+	}
 
-		if !inHistory && strings.HasPrefix(line, "|history") {
-			inHistory = true
-		} else if inHistory {
-			if !strings.HasPrefix(line, "{{TH") {
-				break
-			}
+	player.RealName = infobox.Param("name")
+	player.Team = infobox.Param("team")
+	player.Status = PlayerStatus(strings.ToLower(infobox.Param("status")))
+	if birthDate, err := ParsePartialDate(infobox.Param("birth_date")); err == nil {
+		player.BirthDate = birthDate
+	}
 
-			parts := strings.Split(strings.ReplaceAll(strings.ReplaceAll(line, "{{", ""), "}}", ""), "|")
-			dates := strings.Fields(parts[1])
-			membership := Membership{Join: strings.TrimSpace(dates[0]), Team: strings.TrimSpace(parts[2])}
-			if len(dates) >= 3 {
-				if dateRegex.MatchString(dates[2]) {
-					membership.Leave = strings.TrimSpace(dates[2])
-				}
-			}
-			// Verify that both Join/Leave don't have ?'s
-			if strings.IndexByte(membership.Join, '?') < 0 && strings.IndexByte(membership.Leave, '?') < 0 {
-				player.Memberships = append(player.Memberships, membership)
+	player.Nationalities = numberedParams(infobox, "country")
+	for _, role := range numberedParams(infobox, "role") {
+		player.Roles = append(player.Roles, strings.ToLower(role))
+	}
+
+	for _, site := range socialParams {
+		if link := infobox.Param(site); link != "" {
+			if player.Links == nil {
+				player.Links = make(map[string]string)
 			}
+			player.Links[site] = link
 		}
 	}
+
+	player.Memberships, player.HistoryAuto = parseHistory(infobox)
 	return player
 }
 
-func ExtractWikitext(src interface{}) string {
-	return src.(map[string]interface{})["wikitext"].(map[string]interface{})["*"].(string)
+// numberedParams returns the non-empty values of e.g. |country=|country2=|country3=, stopping at
+// the first missing number
+func numberedParams(t *Template, base string) []string {
+	var values []string
+	if value := t.Param(base); value != "" {
+		values = append(values, value)
+	}
+	for n := 2; t.HasParam(base + strconv.Itoa(n)); n++ {
+		if value := t.Param(base + strconv.Itoa(n)); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// ExtractWikitext pulls the wikitext out of a parse result, i.e. {"wikitext":{"*":"..."}}
+func ExtractWikitext(src interface{}) (string, error) {
+	parse, ok := src.(map[string]interface{})
+	if !ok {
+		return "", malformed("parse result is not an object")
+	}
+	wikitext, ok := parse["wikitext"].(map[string]interface{})
+	if !ok {
+		return "", malformed("parse result has no wikitext")
+	}
+	text, ok := wikitext["*"].(string)
+	if !ok {
+		return "", malformed("wikitext is not a string")
+	}
+	return text, nil
 }
 
 // #REDIRECT [[Turbopolsa]]
@@ -213,15 +229,44 @@ func IsRedirectTo(wikitext string) (bool, string) {
 	return false, ""
 }
 
-// FindSectionIndex finds the section that has `participants` as the line/anchor
+// FindSection finds the first section whose heading contains sectionTitle, e.g. "participants",
+// in a whole page's wikitext. It returns the section's index, counted the way the API counts them
+// (the text before the first heading is 0), and its wikitext including any subsections. The index
+// is -1 if there's no such section.
+func FindSection(wikitext string, sectionTitle string) (int, string) {
+	headings := headingRegex.FindAllStringSubmatchIndex(wikitext, -1)
+	for i, heading := range headings {
+		title := wikitext[heading[4]:heading[5]]
+		if !strings.Contains(strings.ToLower(title), sectionTitle) {
+			continue
+		}
+		level := heading[3] - heading[2]
+		end := len(wikitext)
+		for _, next := range headings[i+1:] {
+			if next[3]-next[2] <= level {
+				end = next[0]
+				break
+			}
+		}
+		return i + 1, wikitext[heading[0]:end]
+	}
+	return -1, ""
+}
+
+// FindSectionIndex finds the section that has `participants` as the line/anchor. Sections that
+// don't look like the API's, or that can't be fetched by index (e.g. transcluded ones with indexes
+// like "T-1"), are skipped.
 func FindSectionIndex(sections []map[string]interface{}, sectionTitle string) int {
 	for _, section := range sections {
-		if strings.Contains(strings.ToLower(section["line"].(string)), sectionTitle) ||
-			strings.Contains(strings.ToLower(section["anchor"].(string)), sectionTitle) {
-			num, err := strconv.Atoi(section["index"].(string))
+		line, _ := section["line"].(string)
+		anchor, _ := section["anchor"].(string)
+		if strings.Contains(strings.ToLower(line), sectionTitle) ||
+			strings.Contains(strings.ToLower(anchor), sectionTitle) {
+			index, _ := section["index"].(string)
+			num, err := strconv.Atoi(index)
 			if err != nil {
 				fmt.Println("Unable to convert index to number", err)
-				os.Exit(1)
+				continue
 			}
 			return num
 		}
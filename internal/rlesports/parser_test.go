@@ -0,0 +1,179 @@
+package rlesports
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Run `go test ./internal/rlesports -update` to rewrite the golden files after an intended change
+// in parser output
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// tournamentGolden is everything parsed from a tournament page
+type tournamentGolden struct {
+	Start  PartialDate `json:"start"`
+	End    PartialDate `json:"end"`
+	Region Region      `json:"region"`
+	Teams  []Team      `json:"teams"`
+}
+
+// playerGolden is everything parsed from a player page
+type playerGolden struct {
+	Redirect    string `json:"redirect,omitempty"`
+	HistoryAuto bool   `json:"historyAuto,omitempty"`
+	Player      Player `json:"player"`
+}
+
+// forEachSnapshot runs fn on every testdata/<dir>/*.wikitext and compares its output against the
+// .golden.json next to it
+func forEachSnapshot(t *testing.T, dir string, fn func(wikitext string) interface{}) {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join("testdata", dir, "*.wikitext"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("no snapshots in testdata/%s", dir)
+	}
+
+	for _, file := range files {
+		file := file
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".wikitext"), func(t *testing.T) {
+			wikitext, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			compareGolden(t, strings.TrimSuffix(file, ".wikitext")+".golden.json", fn(string(wikitext)))
+		})
+	}
+}
+
+// compareGolden compares v, as JSON, against the golden file, or rewrites the golden file with -update
+func compareGolden(t *testing.T, golden string, v interface{}) {
+	t.Helper()
+
+	got, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	if *update {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (run with -update if this is intended)\ngot:\n%s\nwant:\n%s", golden, got, want)
+	}
+}
+
+func TestParseTournamentGolden(t *testing.T) {
+	forEachSnapshot(t, "tournaments", func(wikitext string) interface{} {
+		var g tournamentGolden
+		g.Start, g.End, g.Region = ParseStartEndRegion(wikitext)
+		g.Teams = ParseTeams(wikitext, g.Region)
+		return g
+	})
+}
+
+func TestParsePlayerGolden(t *testing.T) {
+	forEachSnapshot(t, "players", func(wikitext string) interface{} {
+		var g playerGolden
+		_, g.Redirect = IsRedirectTo(wikitext)
+		g.Player = ParsePlayer(wikitext)
+		g.HistoryAuto = g.Player.HistoryAuto
+		return g
+	})
+}
+
+func TestParseHistory(t *testing.T) {
+	player := ParsePlayer(`{{Infobox player
+|id=A
+|history=
+{{TH|2017-03-?? — 2017-06-??|[[Evil Geniuses]] ''(Loan)''}}
+{{TH|2017-06-?? — Present|[[NRG Esports]]}}
+{{TH|2019-03-?? — 2019-04-??|[[Rogue]]|(Substitute)}}
+{{TH||[[Cloud9]]}}
+}}`)
+
+	want := []Membership{
+		{Join: PartialDate{Year: 2017, Month: 3, Precision: PrecisionMonth}, Leave: PartialDate{Year: 2017, Month: 6, Precision: PrecisionMonth}, Team: "Evil Geniuses", Kind: MembershipLoan},
+		{Join: PartialDate{Year: 2017, Month: 6, Precision: PrecisionMonth}, Team: "NRG Esports", Kind: MembershipPlayer},
+		{Join: PartialDate{Year: 2019, Month: 3, Precision: PrecisionMonth}, Leave: PartialDate{Year: 2019, Month: 4, Precision: PrecisionMonth}, Team: "Rogue", Kind: MembershipSubstitute},
+	}
+	if !reflect.DeepEqual(player.Memberships, want) {
+		t.Errorf("memberships = %+v", player.Memberships)
+	}
+}
+
+func TestParseRenderedHistory(t *testing.T) {
+	// Rows as tables or as plain lines, with team icons and display text around the team's link
+	rendered := `<div class="infobox-center"><table>
+<tr><td class="th-mono">2017-03-??</td><td>—</td><td class="th-mono">2017-06-??</td><td><span>[[File:EG logo.png|link=Evil Geniuses]]</span> [[Evil Geniuses|EG]] ''(Loan)''</td></tr>
+<tr><td class="th-mono">2017-06-12</td><td>—</td><td class="th-mono">'''Present'''</td><td>[[NRG Esports]]</td></tr>
+</table></div>
+2019-03-?? – 2019-04-?? [[Rogue]] (Substitute)
+2020-01-01 — 2020-02-01 No team`
+
+	want := []Membership{
+		{Join: PartialDate{Year: 2017, Month: 3, Precision: PrecisionMonth}, Leave: PartialDate{Year: 2017, Month: 6, Precision: PrecisionMonth}, Team: "Evil Geniuses", Kind: MembershipLoan},
+		{Join: NewDate(2017, 6, 12), Team: "NRG Esports", Kind: MembershipPlayer},
+		{Join: PartialDate{Year: 2019, Month: 3, Precision: PrecisionMonth}, Leave: PartialDate{Year: 2019, Month: 4, Precision: PrecisionMonth}, Team: "Rogue", Kind: MembershipSubstitute},
+	}
+	if got := ParseRenderedHistory(rendered); !reflect.DeepEqual(got, want) {
+		t.Errorf("memberships = %+v", got)
+	}
+}
+
+func TestParseMatchesGolden(t *testing.T) {
+	forEachSnapshot(t, "matches", func(wikitext string) interface{} {
+		return ParseMatches(wikitext)
+	})
+}
+
+func TestParsePlacementsGolden(t *testing.T) {
+	forEachSnapshot(t, "prizepool", func(wikitext string) interface{} {
+		return ParsePlacements(wikitext)
+	})
+}
+
+func TestParseGroupTablesGolden(t *testing.T) {
+	forEachSnapshot(t, "standings", func(wikitext string) interface{} {
+		return ParseGroupTables(wikitext)
+	})
+}
+
+func TestFindSection(t *testing.T) {
+	const page = `{{Infobox league|name=Qualifier}}
+==Format==
+Best of 5
+==Participants==
+{{TeamCard|team=Cloud9}}
+===Notes===
+Subsections are part of it.
+==Results==
+{{Bracket}}`
+
+	index, section := FindSection(page, PlayersSectionTitle)
+	if index != 2 || section != "==Participants==\n{{TeamCard|team=Cloud9}}\n===Notes===\nSubsections are part of it.\n" {
+		t.Errorf("got %d, %q", index, section)
+	}
+	if index, section := FindSection(page, "prize pool"); index != -1 || section != "" {
+		t.Errorf("prize pool: got %d, %q", index, section)
+	}
+}
@@ -1,16 +1,25 @@
 package rlesports
 
-func UpdatePlayerNames(storage Storage) {
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// UpdatePlayerNames resolves alternate names for every player in the saved tournaments. Players
+// that fail are left for the next run, and the run fails at the end if any did. If ctx is
+// cancelled the players processed so far are still saved. A summary is printed either way.
+func UpdatePlayerNames(ctx context.Context, client *LiquipediaClient, storage Storage) error {
 	// Given the current tournaments, go through and fetch relevant players' alternate names. We use
 	// the Liquipedia article title as the "canonical" name and create a map of all non-canonical
 	// names to the canonical name (basically an inverse of the mapping data found on the player's
 	// Liquipedia page)
 
 	// Players processed is names that are within tournament team rosters
-	var processedPlayersArr []string
-	processedPlayersArr, err := storage.GetProcessedPlayers()
+	processedPlayersArr, err := storage.GetProcessedPlayers(ctx)
 	if err != nil {
-		processedPlayersArr = make([]string, 0)
+		return &StorageError{err}
 	}
 	processedPlayers := make(map[string]bool)
 	for _, pn := range processedPlayersArr {
@@ -18,36 +27,100 @@ func UpdatePlayerNames(storage Storage) {
 	}
 
 	// The values of playerNames are the canonical page names on Liquipedia
-	var playerNames map[string]string
-	playerNames, err = storage.GetPlayerNames()
+	playerNames, err := storage.GetPlayerNames(ctx)
+	if err != nil {
+		return &StorageError{err}
+	}
+
+	tournaments, err := storage.GetAllTournaments(ctx)
 	if err != nil {
-		playerNames = make(map[string]string)
+		return &StorageError{err}
 	}
 
-	tournaments := storage.GetAllTournaments()
+	report := UpdateReport{Kind: "players"}
+	defer report.Summary(os.Stdout)
 
+	// Collect everyone we haven't seen yet so that they can be fetched in batches. Team cards
+	// can link a player's name to their page, which saves guessing the page from the name.
+	var pending []string
+	pages := make(map[string][]string)
 	for _, tourney := range tournaments {
 		for _, team := range tourney.Teams {
-			for _, playerName := range team.Players {
-				if processedPlayers[playerName] {
+			for _, entry := range teamPlayers(team) {
+				if processedPlayers[entry.Name] {
 					continue
 				}
+				page := entry.Page()
+				if _, ok := pages[page]; !ok {
+					pending = append(pending, page)
+				}
+				if !containsString(pages[page], entry.Name) {
+					pages[page] = append(pages[page], entry.Name)
+				}
+			}
+		}
+	}
 
-				processedPlayers[playerName] = true
-				wikitext := FetchPlayer(playerName)
-
-				// First check if it's a redirect
-				if ok, to := IsRedirectTo(wikitext); ok {
-					// Populate map
-					playerNames[playerName] = to
-				} else {
-					player := ParsePlayer(wikitext)
+Batches:
+	for start := 0; start < len(pending); start += queryBatchSize {
+		end := start + queryBatchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		batchPages := pending[start:end]
 
-					for _, alt := range player.AlternateIDs {
-						playerNames[alt] = player.Name
+		batch, err := client.FetchPages(ctx, batchPages)
+		if err != nil {
+			if ctx.Err() != nil {
+				break Batches
+			}
+			// Fall back to fetching one player at a time
+			fmt.Println("Unable to fetch players in a batch, fetching individually", err)
+			for _, page := range batchPages {
+				if err := fetchPlayerNames(ctx, client, page, pages[page], playerNames); err != nil {
+					if ctx.Err() != nil {
+						break Batches
+					}
+					if !errors.Is(err, ErrPageMissing) {
+						// Leave unprocessed so that the next run tries again
+						fmt.Println("Skipping", page, err)
+						report.Fail(page, err)
+						continue
 					}
+					fmt.Println("No page for", page)
+				}
+				for _, playerName := range pages[page] {
+					processedPlayers[playerName] = true
 				}
+				report.Succeeded++
 			}
+			continue
+		}
+
+		missing := make(map[string]bool, len(batch.Missing))
+		for _, page := range batch.Missing {
+			fmt.Println("No page for", page)
+			missing[page] = true
+		}
+		for _, page := range batchPages {
+			wikitext, found := batch.Wikitext[page]
+			if !found && !missing[page] {
+				// Neither found nor missing, so we know nothing about it yet. Leave it unprocessed so
+				// that the next run tries again.
+				report.Fail(page, malformed("no content for %v in batch response", page))
+				continue
+			}
+			if found {
+				canonical := page
+				if to, ok := batch.Redirects[page]; ok {
+					canonical = to
+				}
+				addPlayerNames(pages[page], canonical, ParsePlayer(wikitext), playerNames)
+			}
+			for _, playerName := range pages[page] {
+				processedPlayers[playerName] = true
+			}
+			report.Succeeded++
 		}
 	}
 
@@ -57,6 +130,97 @@ func UpdatePlayerNames(storage Storage) {
 		processedPlayersArr = append(processedPlayersArr, p)
 	}
 
-	storage.SaveProcessedPlayers(processedPlayersArr)
-	storage.SavePlayerNames(playerNames)
+	// Names first, so that players are never marked as processed without their names being saved
+	if err := storage.SavePlayerNames(ctx, playerNames); err != nil {
+		return &StorageError{err}
+	}
+	if err := storage.SaveProcessedPlayers(ctx, processedPlayersArr); err != nil {
+		return &StorageError{err}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return report.Err()
+}
+
+// teamPlayers is everyone who played for the team. Older tournaments were saved before we kept
+// full rosters, so fall back to the list of player names.
+func teamPlayers(team Team) []RosterEntry {
+	if len(team.Roster) == 0 {
+		entries := make([]RosterEntry, 0, len(team.Players))
+		for _, name := range team.Players {
+			entries = append(entries, RosterEntry{Name: name, Role: RolePlayer})
+		}
+		return entries
+	}
+
+	var entries []RosterEntry
+	for _, entry := range team.Roster {
+		if entry.Role == RolePlayer {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// fetchPlayerNames is the one-page-at-a-time way of resolving the names of the player with the
+// given page
+func fetchPlayerNames(ctx context.Context, client *LiquipediaClient, page string, names []string, playerNames map[string]string) error {
+	wikitext, err := client.FetchPlayer(ctx, page)
+	if err != nil {
+		return err
+	}
+
+	// First check if it's a redirect
+	if ok, to := IsRedirectTo(wikitext); ok {
+		addPlayerNames(names, to, Player{}, playerNames)
+	} else {
+		addPlayerNames(names, page, ParsePlayer(wikitext), playerNames)
+	}
+	return nil
+}
+
+// FetchPlayerDetails gets everything about a player from their page, following a redirect if
+// there is one. Team history that comes from transfers is fetched as well.
+func FetchPlayerDetails(ctx context.Context, client *LiquipediaClient, page string) (Player, error) {
+	wikitext, err := client.FetchPlayer(ctx, page)
+	if err != nil {
+		return Player{}, err
+	}
+	if ok, to := IsRedirectTo(wikitext); ok {
+		page = to
+		if wikitext, err = client.FetchPlayer(ctx, page); err != nil {
+			return Player{}, err
+		}
+	}
+
+	player := ParsePlayer(wikitext)
+	if player.HistoryAuto {
+		if player.Memberships, err = FetchTeamHistory(ctx, client, page); err != nil {
+			return player, err
+		}
+	}
+	return player, nil
+}
+
+// addPlayerNames maps the names a player appeared under, as well as all of their alternate IDs, to
+// their canonical name
+func addPlayerNames(names []string, canonical string, player Player, playerNames map[string]string) {
+	for _, name := range names {
+		if name != canonical {
+			playerNames[name] = canonical
+		}
+	}
+	for _, alt := range player.AlternateIDs {
+		playerNames[alt] = player.Name
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
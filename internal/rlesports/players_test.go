@@ -0,0 +1,108 @@
+package rlesports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestUpdatePlayerNamesBatch(t *testing.T) {
+	inTempDir(t)
+	ctx := context.Background()
+	var storage JsonStorage
+
+	err := JsonSaveTournaments([]Tournament{{
+		Name:  "Rocket League Championship Series/Season 1",
+		Teams: []Team{{Name: "iBUYPOWER Cosmic", Players: []string{"Kronovi", "Turbopolsa", "Nobody", "Lost"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lost comes back as neither content nor missing, e.g. if the response was cut short
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"query": {
+				"redirects": [{"from": "Turbopolsa", "to": "TurboPolsa"}],
+				"pages": {
+					"1": {"title": "Kronovi", "revisions": [{"*": "{{Infobox player|id=Kronovi|ids=Kronovi_}}"}]},
+					"2": {"title": "TurboPolsa", "revisions": [{"*": "{{Infobox player|id=TurboPolsa}}"}]},
+					"-1": {"title": "Nobody", "missing": ""}
+				}
+			}
+		}`)
+	})
+
+	if err := UpdatePlayerNames(ctx, client, storage); err == nil {
+		t.Error("no error for a player that wasn't resolved")
+	}
+
+	processed, err := storage.GetProcessedPlayers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(processed)
+	if !reflect.DeepEqual(processed, []string{"Kronovi", "Nobody", "Turbopolsa"}) {
+		t.Errorf("processed = %v", processed)
+	}
+
+	names, err := storage.GetPlayerNames(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names["Turbopolsa"] != "TurboPolsa" || names["Kronovi_"] != "Kronovi" {
+		t.Errorf("names = %v", names)
+	}
+}
+
+func TestFetchPlayerDetails(t *testing.T) {
+	page, err := os.ReadFile(filepath.Join("testdata", "players", "jstn.wikitext"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		rendered string
+		teams    []string
+	}{
+		{"history", `<tr><td>2017-06-12</td><td>—</td><td>Present</td><td>[[NRG Esports]]</td></tr>`, []string{"NRG Esports"}},
+		// No rows means the rendered history isn't what we expect, so that's an error
+		{"no history", `<div class="infobox-center"></div>`, nil},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				q := r.URL.Query()
+				if q.Get("action") == "expandtemplates" {
+					if q.Get("title") != "jstn." || q.Get("text") != "{{TeamHistoryAuto}}" {
+						t.Errorf("query = %v", q.Encode())
+					}
+					body, _ := json.Marshal(map[string]interface{}{"expandtemplates": map[string]string{"wikitext": tc.rendered}})
+					w.Write(body)
+					return
+				}
+				body, _ := json.Marshal(map[string]interface{}{"parse": map[string]interface{}{"wikitext": map[string]string{"*": string(page)}}})
+				w.Write(body)
+			})
+
+			player, err := FetchPlayerDetails(context.Background(), client, "jstn.")
+			var teams []string
+			for _, m := range player.Memberships {
+				teams = append(teams, m.Team)
+			}
+			if (err != nil) != (tc.teams == nil) || !reflect.DeepEqual(teams, tc.teams) {
+				t.Errorf("got %v, %v", player.Memberships, err)
+			}
+			if tc.teams == nil && !errors.Is(err, ErrMalformedResponse) {
+				t.Errorf("got %v, want ErrMalformedResponse", err)
+			}
+		})
+	}
+}
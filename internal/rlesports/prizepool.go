@@ -0,0 +1,199 @@
+package rlesports
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/* Final placements and prize money from prize pool tables */
+
+var (
+	placeRegex     = regexp.MustCompile(`^\s*([0-9]+)(?:\s*[-–—]\s*([0-9]+))?`)
+	teamParamRegex = regexp.MustCompile(`^team[0-9]*$`)
+	nonAlnumRegex  = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Placing is a single team's result in a prize pool table
+type Placing struct {
+	// Team is as given in the slot, which may be a team template's code, e.g. "nrg" for
+	// {{Team|nrg}}
+	Team string
+	// Link is the team's page, if the slot links to it
+	Link      string `json:",omitempty"`
+	Placement Placement
+	Prize     *Prize
+}
+
+func (p Placing) String() string {
+	return fmt.Sprintf("%v (%v)", p.Team, p.Placement)
+}
+
+// ParsePlacements reads prize pool tables, either
+// {{prize pool start}} {{prize pool slot|place=3-4|usdprize=5,000|team1=A|team2=B}} {{prize pool end}}
+// or {{Prize pool start|localcurrency=eur}} {{Slot|place=1|localprize=10,000|{{TeamOpponent|A}}}}
+func ParsePlacements(wikitext string) []Placing {
+	placings := []Placing{}
+
+	currency := ""
+	ParseWikitext(wikitext).Walk(func(t *Template) {
+		switch {
+		case t.Is("prize pool start"):
+			currency = strings.ToUpper(t.Param("localcurrency"))
+		case t.Is("prize pool slot", "Slot"):
+			placement, ok := parsePlace(t.Param("place"))
+			if !ok {
+				return
+			}
+			prize := parsePrize(t, currency)
+			for _, team := range slotTeams(t) {
+				team.Placement, team.Prize = placement, prize
+				placings = append(placings, team)
+			}
+		}
+	})
+
+	return placings
+}
+
+// parsePlace reads "1", "3-4", "5–8", etc.
+func parsePlace(place string) (Placement, bool) {
+	res := placeRegex.FindStringSubmatch(place)
+	if res == nil {
+		return Placement{}, false
+	}
+	start, _ := strconv.Atoi(res[1])
+	end := start
+	if res[2] != "" {
+		end, _ = strconv.Atoi(res[2])
+	}
+	if start <= 0 || end < start {
+		return Placement{}, false
+	}
+	return Placement{Start: start, End: end}, true
+}
+
+// parsePrize reads the prize of a slot, preferring USD
+func parsePrize(slot *Template, localCurrency string) *Prize {
+	if amount, ok := parseAmount(slot.Param("usdprize")); ok {
+		return &Prize{Amount: amount, Currency: "USD"}
+	}
+	if amount, ok := parseAmount(slot.Param("localprize")); ok && localCurrency != "" {
+		return &Prize{Amount: amount, Currency: localCurrency}
+	}
+	return nil
+}
+
+// parseAmount reads amounts like "25,000" or "$1,500.50"
+func parseAmount(s string) (float64, bool) {
+	s = strings.TrimSpace(strings.NewReplacer(",", "", "$", "", "€", "", "£", "").Replace(s))
+	amount, err := strconv.ParseFloat(s, 64)
+	if err != nil || amount <= 0 {
+		return 0, false
+	}
+	return amount, true
+}
+
+// slotTeams gets the teams in a slot, which can be given as |team=/|teamN= params or as positional
+// params holding either a plain name, a link or an *Opponent template. Only Team and Link are
+// filled in.
+func slotTeams(slot *Template) []Placing {
+	var teams []Placing
+	for _, param := range slot.Params {
+		if !param.Positional && !teamParamRegex.MatchString(param.Name) {
+			continue
+		}
+		name, _ := parseOpponent(param.Value)
+		// Placeholders for undecided places
+		if name == "" || strings.EqualFold(name, "tbd") {
+			continue
+		}
+		team := Placing{Team: name}
+		if links := param.Value.Links(); len(links) > 0 {
+			team.Link = linkPage(links[0].Target)
+		}
+		param.Value.Walk(func(t *Template) {
+			if team.Link == "" && isTeamTemplate(t) {
+				team.Link = linkPage(t.Param("link"))
+			}
+		})
+		teams = append(teams, team)
+	}
+	return teams
+}
+
+// teamKey reduces a team's name to lower case letters and digits, so that "NRG_Esports" and
+// "NRG eSports" are the same team
+func teamKey(name string) string {
+	return nonAlnumRegex.ReplaceAllString(strings.ToLower(name), "")
+}
+
+// matchesTemplateCode reports whether code could be the team template code for name, i.e. it's the
+// start of the name, like "nrg" for "NRG Esports", or its initials, like "tl" for "Team Liquid"
+func matchesTemplateCode(code string, name string) bool {
+	key := teamKey(code)
+	if key == "" {
+		return false
+	}
+	initials := ""
+	for _, word := range strings.Fields(nonAlnumRegex.ReplaceAllString(strings.ToLower(name), " ")) {
+		initials += word[:1]
+	}
+	return strings.HasPrefix(teamKey(name), key) || key == initials
+}
+
+// ApplyPlacements sets each team's placement and prize from the matching placing, clearing them
+// for teams without one. Placings are matched on the team's name or the page they link to, and
+// failing that on a team template code that's the start or the initials of exactly one team's name.
+// Placings that match no team are returned.
+func ApplyPlacements(teams []Team, placings []Placing) (unmatched []Placing) {
+	for i := range teams {
+		teams[i].Placement, teams[i].Prize, teams[i].Won = nil, nil, false
+	}
+	apply := func(team *Team, placing Placing) {
+		placement := placing.Placement
+		team.Placement = &placement
+		team.Prize = placing.Prize
+		team.Won = placement.Start == 1 && placement.End == 1
+	}
+
+	var codes []Placing
+	for _, placing := range placings {
+		matched := false
+		for i := range teams {
+			key := teamKey(teams[i].Name)
+			if teams[i].Placement == nil && key != "" && (key == teamKey(placing.Team) || key == teamKey(placing.Link)) {
+				apply(&teams[i], placing)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			codes = append(codes, placing)
+		}
+	}
+
+	// Template codes only once every full name has been matched, so that they can't take a team
+	// that's placed by name
+	for _, placing := range codes {
+		var match *Team
+		for i := range teams {
+			if teams[i].Placement != nil || !matchesTemplateCode(placing.Team, teams[i].Name) {
+				continue
+			}
+			if match != nil {
+				// Ambiguous
+				match = nil
+				break
+			}
+			match = &teams[i]
+		}
+		if match == nil {
+			unmatched = append(unmatched, placing)
+			continue
+		}
+		apply(match, placing)
+	}
+	return unmatched
+}
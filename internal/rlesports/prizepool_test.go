@@ -0,0 +1,61 @@
+package rlesports
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyPlacements(t *testing.T) {
+	// Slots give teams by template code, link or name
+	const wikitext = `{{Prize pool start}}
+{{Slot|place=1|usdprize=10,000|{{Team|nrg}}}}
+{{Slot|place=2|{{TeamOpponent|c9|link=Cloud9}}}}
+{{Slot|place=3-4|{{Team|tl}}|[[G2 Esports|G2]]}}
+{{Slot|place=5-6|Renault_Vitality|{{Team|ibp}}}}
+{{Slot|place=7-8|{{Team|dig}}|{{Team|dignitas}}}}
+{{Prize pool end}}`
+
+	teams := []Team{
+		{Name: "NRG Esports"},
+		{Name: "Cloud9"},
+		{Name: "Team Liquid"},
+		{Name: "G2 Esports"},
+		{Name: "Renault Vitality"},
+		{Name: "iBUYPOWER Cosmic"},
+		{Name: "Dignitas"},
+		// Placed last time, but not any more
+		{Name: "Mock-it eSports", Placement: &Placement{Start: 1, End: 1}, Won: true},
+	}
+	unmatched := ApplyPlacements(teams, ParsePlacements(wikitext))
+
+	want := map[string]*Placement{
+		"NRG Esports":      {Start: 1, End: 1},
+		"Cloud9":           {Start: 2, End: 2},
+		"Team Liquid":      {Start: 3, End: 4},
+		"G2 Esports":       {Start: 3, End: 4},
+		"Renault Vitality": {Start: 5, End: 6},
+		// Placed by name, which leaves the code with nothing to match
+		"Dignitas":         {Start: 7, End: 8},
+		"iBUYPOWER Cosmic": nil,
+		"Mock-it eSports":  nil,
+	}
+	for _, team := range teams {
+		if !reflect.DeepEqual(team.Placement, want[team.Name]) {
+			t.Errorf("%v: placement %v, want %v", team.Name, team.Placement, want[team.Name])
+		}
+		if team.Won != (team.Name == "NRG Esports") {
+			t.Errorf("%v: won %v", team.Name, team.Won)
+		}
+	}
+	if teams[0].Prize == nil || teams[0].Prize.Amount != 10000 {
+		t.Errorf("NRG Esports: prize %+v", teams[0].Prize)
+	}
+
+	var names []string
+	for _, placing := range unmatched {
+		names = append(names, placing.Team)
+	}
+	if !reflect.DeepEqual(names, []string{"ibp", "dig"}) {
+		t.Errorf("unmatched = %v", unmatched)
+	}
+}
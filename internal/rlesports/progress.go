@@ -0,0 +1,40 @@
+package rlesports
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProgressKind says what a ProgressEvent is about
+type ProgressKind uint8
+
+// Kinds of progress events
+const (
+	// ProgressWait is sent before sleeping for the rate limiter
+	ProgressWait ProgressKind = iota
+	// ProgressRetry is sent before sleeping ahead of a retry
+	ProgressRetry
+)
+
+// ProgressEvent reports what the client is doing during long waits
+type ProgressEvent struct {
+	Kind ProgressKind
+	// Action is the API action ("parse", "query") of the request being made
+	Action string
+	// Wait is how long the client is about to sleep for
+	Wait time.Duration
+	// Attempt is the retry number, starting at 1
+	Attempt int
+	// Err is what caused the retry
+	Err error
+}
+
+func (e ProgressEvent) String() string {
+	switch e.Kind {
+	case ProgressWait:
+		return fmt.Sprintf("waiting %v for %s rate limit", e.Wait.Round(time.Second), e.Action)
+	case ProgressRetry:
+		return fmt.Sprintf("retry %d of %s request in %v: %v", e.Attempt, e.Action, e.Wait.Round(time.Second), e.Err)
+	}
+	return ""
+}
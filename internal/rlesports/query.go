@@ -0,0 +1,238 @@
+package rlesports
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+/* Batched requests through action=query */
+
+// MediaWiki accepts at most 50 titles per query for regular clients
+const queryBatchSize = 50
+
+// PageInfo is the revision information of a single page
+type PageInfo struct {
+	Title      string
+	RevisionID int64
+	Touched    string
+	Missing    bool
+}
+
+type queryInfoResult struct {
+	Query struct {
+		Normalized []struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		} `json:"normalized"`
+		Pages map[string]struct {
+			Title     string  `json:"title"`
+			LastRevID int64   `json:"lastrevid"`
+			Touched   string  `json:"touched"`
+			Missing   *string `json:"missing"`
+		} `json:"pages"`
+	} `json:"query"`
+	Error *APIError `json:"error"`
+}
+
+// FetchPageInfo gets the latest revision of each of the given pages, batching titles to keep the
+// number of requests down. The result is keyed on the titles as given.
+func (c *LiquipediaClient) FetchPageInfo(ctx context.Context, titles []string) (map[string]PageInfo, error) {
+	infos := make(map[string]PageInfo, len(titles))
+
+	for start := 0; start < len(titles); start += queryBatchSize {
+		end := start + queryBatchSize
+		if end > len(titles) {
+			end = len(titles)
+		}
+		batch := titles[start:end]
+
+		opts := url.Values{
+			"action": {"query"},
+			"prop":   {"info"},
+			"titles": {strings.Join(batch, "|")},
+		}
+		resp, err := c.call(ctx, opts, false)
+		if err != nil {
+			return nil, fmt.Errorf("fetching page info: %w", err)
+		}
+
+		var res queryInfoResult
+		if err := json.Unmarshal(resp, &res); err != nil {
+			return nil, malformed("%v", err)
+		}
+		if res.Error != nil {
+			return nil, res.Error
+		}
+
+		// Pages come back under their normalized titles, e.g. underscores become spaces
+		normalized := make(map[string]string)
+		for _, n := range res.Query.Normalized {
+			normalized[n.From] = n.To
+		}
+		byTitle := make(map[string]PageInfo)
+		for _, page := range res.Query.Pages {
+			byTitle[page.Title] = PageInfo{
+				Title:      page.Title,
+				RevisionID: page.LastRevID,
+				Touched:    page.Touched,
+				Missing:    page.Missing != nil,
+			}
+		}
+
+		for _, title := range batch {
+			lookup := title
+			if to, ok := normalized[title]; ok {
+				lookup = to
+			}
+			if info, ok := byTitle[lookup]; ok {
+				infos[title] = info
+			}
+		}
+	}
+
+	return infos, nil
+}
+
+// PageBatch is the result of fetching several pages at once. Everything is keyed on the titles as
+// they were requested.
+type PageBatch struct {
+	// Wikitext is the content of each page that was found, after following redirects
+	Wikitext map[string]string
+	// Redirects maps titles that are redirects to the title they redirect to
+	Redirects map[string]string
+	// Missing lists titles that don't exist
+	Missing []string
+}
+
+type queryRevisionsResult struct {
+	Continue map[string]string `json:"continue"`
+	Query    struct {
+		Normalized []struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		} `json:"normalized"`
+		Redirects []struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		} `json:"redirects"`
+		Pages map[string]struct {
+			Title     string  `json:"title"`
+			Missing   *string `json:"missing"`
+			Revisions []struct {
+				// Content is here without rvslots...
+				Content *string `json:"*"`
+				// ...and here with it
+				Slots struct {
+					Main struct {
+						Content *string `json:"*"`
+					} `json:"main"`
+				} `json:"slots"`
+			} `json:"revisions"`
+		} `json:"pages"`
+	} `json:"query"`
+	Error *APIError `json:"error"`
+}
+
+// FetchPages gets the current wikitext of many pages with one request per 50 titles, following
+// redirects along the way. This is much cheaper than FetchPlayer/FetchSection, which are limited to
+// one page per request at a stricter rate.
+func (c *LiquipediaClient) FetchPages(ctx context.Context, titles []string) (PageBatch, error) {
+	batch := PageBatch{
+		Wikitext:  make(map[string]string, len(titles)),
+		Redirects: make(map[string]string),
+	}
+
+	for start := 0; start < len(titles); start += queryBatchSize {
+		end := start + queryBatchSize
+		if end > len(titles) {
+			end = len(titles)
+		}
+		if err := c.fetchPages(ctx, titles[start:end], &batch); err != nil {
+			return PageBatch{}, fmt.Errorf("fetching pages: %w", err)
+		}
+	}
+
+	return batch, nil
+}
+
+// fetchPages fetches a single batch of titles into batch
+func (c *LiquipediaClient) fetchPages(ctx context.Context, titles []string, batch *PageBatch) error {
+	normalized := make(map[string]string)
+	redirects := make(map[string]string)
+	missing := make(map[string]bool)
+	content := make(map[string]string)
+
+	// Large pages can push some content into a continuation, so keep going until there is none
+	cont := map[string]string{}
+	for {
+		opts := url.Values{
+			"action":    {"query"},
+			"prop":      {"revisions"},
+			"rvprop":    {"content"},
+			"rvslots":   {"main"},
+			"titles":    {strings.Join(titles, "|")},
+			"redirects": {"1"},
+		}
+		for k, v := range cont {
+			opts.Set(k, v)
+		}
+
+		resp, err := c.CallAPI(ctx, opts)
+		if err != nil {
+			return err
+		}
+
+		var res queryRevisionsResult
+		if err := json.Unmarshal(resp, &res); err != nil {
+			return malformed("%v", err)
+		}
+		if res.Error != nil {
+			return res.Error
+		}
+
+		for _, n := range res.Query.Normalized {
+			normalized[n.From] = n.To
+		}
+		for _, r := range res.Query.Redirects {
+			redirects[r.From] = r.To
+		}
+		for _, page := range res.Query.Pages {
+			if page.Missing != nil {
+				missing[page.Title] = true
+			}
+			for _, rev := range page.Revisions {
+				if rev.Slots.Main.Content != nil {
+					content[page.Title] = *rev.Slots.Main.Content
+				} else if rev.Content != nil {
+					content[page.Title] = *rev.Content
+				}
+			}
+		}
+
+		if len(res.Continue) == 0 {
+			break
+		}
+		cont = res.Continue
+	}
+
+	for _, title := range titles {
+		resolved := title
+		if to, ok := normalized[resolved]; ok {
+			resolved = to
+		}
+		if to, ok := redirects[resolved]; ok {
+			batch.Redirects[title] = to
+			resolved = to
+		}
+
+		if missing[resolved] {
+			batch.Missing = append(batch.Missing, title)
+		} else if text, ok := content[resolved]; ok {
+			batch.Wikitext[title] = text
+		}
+	}
+	return nil
+}
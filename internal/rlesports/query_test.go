@@ -0,0 +1,144 @@
+package rlesports
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestFetchPages(t *testing.T) {
+	var requests []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("action") != "query" || q.Get("redirects") != "1" || q.Get("rvslots") != "main" {
+			t.Errorf("query = %v", q.Encode())
+		}
+		requests = append(requests, q.Get("rvcontinue"))
+
+		// The first response runs out of room after one page and asks to be continued. Titles come
+		// back normalized and with redirects followed.
+		if q.Get("rvcontinue") == "" {
+			fmt.Fprint(w, `{
+				"continue": {"rvcontinue": "2|200", "continue": "||"},
+				"query": {
+					"normalized": [{"from": "kronovi", "to": "Kronovi"}, {"from": "Fire_Burner", "to": "Fire Burner"}],
+					"redirects": [{"from": "Turbopolsa", "to": "TurboPolsa"}],
+					"pages": {
+						"1": {"title": "Kronovi", "revisions": [{"slots": {"main": {"*": "kronovi text"}}}]},
+						"2": {"title": "TurboPolsa"},
+						"-1": {"title": "Nobody", "missing": ""}
+					}
+				}
+			}`)
+			return
+		}
+		if q.Get("continue") != "||" {
+			t.Errorf("continue = %q", q.Get("continue"))
+		}
+		fmt.Fprint(w, `{
+			"query": {
+				"normalized": [{"from": "kronovi", "to": "Kronovi"}, {"from": "Fire_Burner", "to": "Fire Burner"}],
+				"redirects": [{"from": "Turbopolsa", "to": "TurboPolsa"}],
+				"pages": {
+					"1": {"title": "Kronovi"},
+					"2": {"title": "TurboPolsa", "revisions": [{"*": "turbopolsa text"}]},
+					"3": {"title": "Fire Burner", "revisions": [{"slots": {"main": {"*": "fire burner text"}}}]},
+					"-1": {"title": "Nobody", "missing": ""}
+				}
+			}
+		}`)
+	})
+
+	batch, err := client.FetchPages(context.Background(), []string{"kronovi", "Turbopolsa", "Nobody", "Fire_Burner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(requests, []string{"", "2|200"}) {
+		t.Errorf("requests = %q", requests)
+	}
+
+	// Everything is keyed on the titles as requested
+	wantText := map[string]string{
+		"kronovi":     "kronovi text",
+		"Turbopolsa":  "turbopolsa text",
+		"Fire_Burner": "fire burner text",
+	}
+	if !reflect.DeepEqual(batch.Wikitext, wantText) {
+		t.Errorf("wikitext = %v", batch.Wikitext)
+	}
+	if !reflect.DeepEqual(batch.Redirects, map[string]string{"Turbopolsa": "TurboPolsa"}) {
+		t.Errorf("redirects = %v", batch.Redirects)
+	}
+	if !reflect.DeepEqual(batch.Missing, []string{"Nobody"}) {
+		t.Errorf("missing = %v", batch.Missing)
+	}
+}
+
+func TestFetchPagesBatches(t *testing.T) {
+	var batches [][]string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		titles := strings.Split(r.URL.Query().Get("titles"), "|")
+		batches = append(batches, titles)
+
+		var pages []string
+		for i, title := range titles {
+			pages = append(pages, fmt.Sprintf(`"%d": {"title": %q, "revisions": [{"*": "text"}]}`, i, title))
+		}
+		fmt.Fprintf(w, `{"query": {"pages": {%s}}}`, strings.Join(pages, ","))
+	})
+
+	var titles []string
+	for i := 0; i < 2*queryBatchSize+1; i++ {
+		titles = append(titles, fmt.Sprintf("Player %d", i))
+	}
+	batch, err := client.FetchPages(context.Background(), titles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 3 || len(batches[0]) != queryBatchSize || len(batches[2]) != 1 {
+		t.Errorf("batch sizes = %d", len(batches))
+	}
+	if len(batch.Wikitext) != len(titles) {
+		t.Errorf("got %d pages, want %d", len(batch.Wikitext), len(titles))
+	}
+}
+
+func TestFetchPageInfo(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("prop") != "info" {
+			t.Errorf("query = %v", r.URL.Query().Encode())
+		}
+		fmt.Fprint(w, `{
+			"query": {
+				"normalized": [{"from": "Rocket_League_Championship_Series/Season_1", "to": "Rocket League Championship Series/Season 1"}],
+				"pages": {
+					"10": {"title": "Rocket League Championship Series/Season 1", "lastrevid": 1234, "touched": "2020-01-01T00:00:00Z"},
+					"-1": {"title": "Nothing", "missing": ""}
+				}
+			}
+		}`)
+	})
+
+	infos, err := client.FetchPageInfo(context.Background(), []string{"Rocket_League_Championship_Series/Season_1", "Nothing", "Unlisted"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for k := range infos {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"Nothing", "Rocket_League_Championship_Series/Season_1"}) {
+		t.Errorf("keys = %v", keys)
+	}
+	if info := infos["Rocket_League_Championship_Series/Season_1"]; info.RevisionID != 1234 || info.Missing {
+		t.Errorf("Season 1 = %+v", info)
+	}
+	if !infos["Nothing"].Missing {
+		t.Errorf("Nothing = %+v", infos["Nothing"])
+	}
+}
@@ -0,0 +1,117 @@
+package rlesports
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/* Client-side rate limiting, following https://liquipedia.net/api-terms-of-use */
+
+// Liquipedia allows one action=parse request every 30 seconds, and one of anything else every 2
+const (
+	parseInterval = 30 * time.Second
+	queryInterval = 2 * time.Second
+)
+
+// TokenBucket hands out one token per interval, saving up to burst tokens while idle
+type TokenBucket struct {
+	interval time.Duration
+	burst    float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket creates a full bucket
+func NewTokenBucket(interval time.Duration, burst int) *TokenBucket {
+	return &TokenBucket{interval: interval, burst: float64(burst), tokens: float64(burst)}
+}
+
+// reserve takes a token and returns how long the caller has to wait before using it. Tokens can go
+// negative, which queues up concurrent callers behind each other.
+func (b *TokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.last.IsZero() {
+		b.tokens += float64(now.Sub(b.last)) / float64(b.interval)
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens * float64(b.interval))
+}
+
+// drain empties the bucket until the given time, e.g. when the server tells us to back off
+func (b *TokenBucket) drain(now time.Time, until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.last = now
+	// The next reserve takes a token, so leave one more than the wait calls for
+	if owed := 1 - float64(until.Sub(now))/float64(b.interval); owed < b.tokens {
+		b.tokens = owed
+	}
+}
+
+// RateLimiter keeps separate budgets for action=parse and all other (query) requests
+type RateLimiter struct {
+	Parse *TokenBucket
+	Query *TokenBucket
+}
+
+// NewRateLimiter creates a limiter with Liquipedia's published limits
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		Parse: NewTokenBucket(parseInterval, 1),
+		Query: NewTokenBucket(queryInterval, 1),
+	}
+}
+
+func (l *RateLimiter) bucket(action string) *TokenBucket {
+	if action == "parse" {
+		return l.Parse
+	}
+	return l.Query
+}
+
+// Reserve takes a token for the given API action and returns how long to wait before making the
+// request. A nil limiter never waits.
+func (l *RateLimiter) Reserve(action string) time.Duration {
+	if l == nil {
+		return 0
+	}
+	return l.bucket(action).reserve(time.Now())
+}
+
+// Backoff stops requests for the given API action for d, e.g. as requested by Retry-After
+func (l *RateLimiter) Backoff(action string, d time.Duration) {
+	if l == nil {
+		return
+	}
+	now := time.Now()
+	l.bucket(action).drain(now, now.Add(d))
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
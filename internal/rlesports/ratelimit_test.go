@@ -0,0 +1,186 @@
+package rlesports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Now()
+	bucket := NewTokenBucket(2*time.Second, 1)
+
+	for _, tc := range []struct {
+		at   time.Duration
+		wait time.Duration
+	}{
+		// The first request is free, the next waits out the interval
+		{0, 0},
+		{0, 2 * time.Second},
+		// Callers queue up behind each other
+		{0, 4 * time.Second},
+		// Idle time pays the queue back, but only one token is saved up
+		{10 * time.Second, 0},
+		{20 * time.Second, 0},
+		{21 * time.Second, time.Second},
+	} {
+		if got := bucket.reserve(start.Add(tc.at)); got != tc.wait {
+			t.Errorf("at %v: waited %v, want %v", tc.at, got, tc.wait)
+		}
+	}
+
+	// Draining holds off the next request until the given time
+	now := start.Add(100 * time.Second)
+	bucket.drain(now, now.Add(7*time.Second))
+	if got := bucket.reserve(now); got != 7*time.Second {
+		t.Errorf("after drain: waited %v", got)
+	}
+}
+
+func TestRateLimiterBuckets(t *testing.T) {
+	limiter := NewRateLimiter()
+	near := func(got, want time.Duration) bool {
+		return got <= want && got > want-time.Second
+	}
+
+	if wait := limiter.Reserve("parse"); wait != 0 {
+		t.Errorf("first parse waited %v", wait)
+	}
+	// A parse request doesn't use up the query budget
+	if wait := limiter.Reserve("query"); wait != 0 {
+		t.Errorf("first query waited %v", wait)
+	}
+	if wait := limiter.Reserve("parse"); !near(wait, parseInterval) {
+		t.Errorf("second parse waited %v, want %v", wait, parseInterval)
+	}
+	// Everything that isn't a parse shares the query budget
+	if wait := limiter.Reserve("opensearch"); !near(wait, queryInterval) {
+		t.Errorf("second query waited %v, want %v", wait, queryInterval)
+	}
+
+	limiter.Backoff("query", time.Minute)
+	if wait := limiter.Reserve("query"); !near(wait, time.Minute) {
+		t.Errorf("query after backoff waited %v", wait)
+	}
+
+	var nilLimiter *RateLimiter
+	nilLimiter.Backoff("parse", time.Minute)
+	if wait := nilLimiter.Reserve("parse"); wait != 0 {
+		t.Errorf("nil limiter waited %v", wait)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	for header, want := range map[string]time.Duration{
+		"":                              0,
+		"5":                             5 * time.Second,
+		"0":                             0,
+		"-3":                            0,
+		"soon":                          0,
+		"120":                           2 * time.Minute,
+		"Mon, 01 Jan 2001 00:00:00 GMT": 0,
+	} {
+		if got := parseRetryAfter(header); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", header, got, want)
+		}
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got <= 59*time.Minute || got > time.Hour {
+		t.Errorf("parseRetryAfter(%q) = %v", future, got)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	requests := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			// Rate limits can also come back as a regular response
+			fmt.Fprint(w, `{"error":{"code":"ratelimited","info":"You've exceeded your rate limit."}}`)
+		default:
+			fmt.Fprint(w, `{"parse":{"wikitext":{"*":"text"}}}`)
+		}
+	})
+
+	var events []ProgressEvent
+	client.Progress = func(e ProgressEvent) { events = append(events, e) }
+
+	if _, err := client.FetchSection(context.Background(), "A", 0); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 || len(events) != 2 {
+		t.Fatalf("%d requests, events %v", requests, events)
+	}
+	// Waits double on each attempt
+	if events[0].Wait != time.Millisecond || !errors.Is(events[0].Err, ErrTransport) {
+		t.Errorf("first retry = %+v", events[0])
+	}
+	if events[1].Wait != 2*time.Millisecond || events[1].Attempt != 2 || !errors.Is(events[1].Err, ErrRateLimited) {
+		t.Errorf("second retry = %+v", events[1])
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	client.Limiter = NewRateLimiter()
+
+	// Stop as soon as the client says how long it's going to wait
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var events []ProgressEvent
+	client.Progress = func(e ProgressEvent) {
+		events = append(events, e)
+		cancel()
+	}
+
+	_, err := client.FetchSection(ctx, "A", 0)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v", err)
+	}
+	if len(events) != 1 || events[0].Kind != ProgressRetry || events[0].Wait != 2*time.Minute ||
+		!errors.Is(events[0].Err, ErrRateLimited) {
+		t.Fatalf("events = %+v", events)
+	}
+	// Everyone else using the limiter is held back too
+	if wait := client.Limiter.Reserve("parse"); wait < time.Minute {
+		t.Errorf("next parse waits %v", wait)
+	}
+}
+
+func TestRetryLimits(t *testing.T) {
+	for _, tc := range []struct {
+		status   int
+		requests int
+		want     error
+	}{
+		// Server trouble is retried until MaxRetries runs out
+		{http.StatusServiceUnavailable, 3, ErrTransport},
+		// Client errors never get better
+		{http.StatusNotFound, 1, ErrTransport},
+		{http.StatusForbidden, 1, ErrTransport},
+	} {
+		requests := 0
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(tc.status)
+		})
+		client.MaxRetries = 2
+
+		if _, err := client.FetchSection(context.Background(), "A", 0); !errors.Is(err, tc.want) {
+			t.Errorf("%d: got %v", tc.status, err)
+		}
+		if requests != tc.requests {
+			t.Errorf("%d: %d requests, want %d", tc.status, requests, tc.requests)
+		}
+	}
+}
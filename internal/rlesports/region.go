@@ -0,0 +1,178 @@
+package rlesports
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/* Regions, and how Liquipedia names them */
+
+// Region is represented by an 8-bit unsigned int. The values are stable, since older JSON files
+// stored them as bare integers.
+type Region uint8
+
+// Defined regions
+const (
+	RegionNone Region = iota
+	RegionWorld
+	RegionNorthAmerica
+	RegionEurope
+	RegionOceania
+	RegionSouthAmerica
+	RegionMiddleEast
+	RegionAsiaPacificNorth
+	RegionAsiaPacificSouth
+	RegionSubSaharanAfrica
+	RegionAsiaPacific
+)
+
+// regionInfo describes a region: its code in JSON, its name in Liquipedia page titles, and any
+// other names Liquipedia uses for it in infoboxes and qualifier links. Aliases in capitals, like
+// "NA", are abbreviations and only match in capitals, so that "Na'Vi" isn't North America.
+type regionInfo struct {
+	region  Region
+	code    string
+	name    string
+	aliases []string
+}
+
+// regions is the single mapping between Liquipedia text and Region. RegionWorld has no aliases
+// since it's never named in text, only implied by LANs.
+var regions = []regionInfo{
+	{RegionNone, "", "None", nil},
+	{RegionWorld, "world", "World", nil},
+	{RegionNorthAmerica, "na", "North America", []string{"North America", "NA"}},
+	{RegionEurope, "eu", "Europe", []string{"Europe", "EU"}},
+	{RegionOceania, "oce", "Oceania", []string{"Oceania", "OCE", "ANZ"}},
+	{RegionSouthAmerica, "sam", "South America", []string{"South America", "SAM"}},
+	{RegionMiddleEast, "mena", "Middle East & North Africa", []string{"Middle East & North Africa", "Middle East and North Africa", "Middle East", "MENA"}},
+	{RegionAsiaPacificNorth, "apacn", "Asia-Pacific North", []string{"Asia-Pacific North", "Asia Pacific North", "APAC North", "APAC-N"}},
+	{RegionAsiaPacificSouth, "apacs", "Asia-Pacific South", []string{"Asia-Pacific South", "Asia Pacific South", "APAC South", "APAC-S"}},
+	{RegionSubSaharanAfrica, "ssa", "Sub-Saharan Africa", []string{"Sub-Saharan Africa", "Sub Saharan Africa", "SSA"}},
+	{RegionAsiaPacific, "asia", "Asia-Pacific", []string{"Asia-Pacific", "Asia Pacific", "APAC", "Asia"}},
+}
+
+func (r Region) info() (regionInfo, bool) {
+	for _, info := range regions {
+		if info.region == r {
+			return info, true
+		}
+	}
+	return regionInfo{}, false
+}
+
+// String is the region's name, as used in Liquipedia page titles
+func (r Region) String() string {
+	info, _ := r.info()
+	return info.name
+}
+
+// Code is the short code the region is stored as, e.g. "na". RegionNone's code is "".
+func (r Region) Code() string {
+	info, _ := r.info()
+	return info.code
+}
+
+// ParseRegion finds the region named in Liquipedia text, e.g. an infobox's |country=North America
+// or a qualifier link like "Europe Qualifier #2". Names have to be whole words, and the longest
+// match wins so that "Asia-Pacific North" isn't mistaken for "Asia". Abbreviations are matched
+// case-sensitively. Returns RegionNone if no region is named.
+func ParseRegion(text string) Region {
+	lower := strings.ToLower(text)
+	best, bestLen := RegionNone, 0
+	for _, info := range regions {
+		for _, alias := range info.aliases {
+			if len(alias) <= bestLen {
+				continue
+			}
+			found := false
+			if alias == strings.ToUpper(alias) {
+				found = containsWord(text, alias)
+			} else {
+				found = containsWord(lower, strings.ToLower(alias))
+			}
+			if found {
+				best, bestLen = info.region, len(alias)
+			}
+		}
+	}
+	return best
+}
+
+// containsWord reports whether word appears in text without letters, digits or apostrophes on
+// either side, so that "Sam's" doesn't contain "Sam"
+func containsWord(text, word string) bool {
+	isWordByte := func(s string, i int) bool {
+		return i >= 0 && i < len(s) && (unicode.IsLetter(rune(s[i])) || unicode.IsDigit(rune(s[i])) || s[i] == '\'')
+	}
+	for start := 0; start < len(text); {
+		i := strings.Index(text[start:], word)
+		if i < 0 {
+			return false
+		}
+		i += start
+		if !isWordByte(text, i-1) && !isWordByte(text, i+len(word)) {
+			return true
+		}
+		start = i + 1
+	}
+	return false
+}
+
+// RegionsOf returns the distinct regions of teams, sorted by Region value, leaving out teams
+// without one and RegionWorld
+func RegionsOf(teams []Team) []Region {
+	var regions []Region
+	seen := make(map[Region]bool)
+	for _, team := range teams {
+		if team.Region != RegionNone && team.Region != RegionWorld && !seen[team.Region] {
+			seen[team.Region] = true
+			regions = append(regions, team.Region)
+		}
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i] < regions[j] })
+	return regions
+}
+
+// MarshalText writes the region's code
+func (r Region) MarshalText() ([]byte, error) {
+	info, ok := r.info()
+	if !ok {
+		return nil, fmt.Errorf("unknown region %d", r)
+	}
+	return []byte(info.code), nil
+}
+
+// UnmarshalText reads a region code
+func (r *Region) UnmarshalText(text []byte) error {
+	for _, info := range regions {
+		if strings.EqualFold(string(text), info.code) {
+			*r = info.region
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown region %q", text)
+}
+
+// UnmarshalJSON reads a region code, or the bare integer older JSON files have
+func (r *Region) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if n, err := strconv.ParseUint(string(data), 10, 8); err == nil {
+		if _, ok := Region(n).info(); !ok {
+			return fmt.Errorf("unknown region %d", n)
+		}
+		*r = Region(n)
+		return nil
+	}
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid region %s", data)
+	}
+	return r.UnmarshalText([]byte(s))
+}
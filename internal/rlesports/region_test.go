@@ -0,0 +1,44 @@
+package rlesports
+
+import "testing"
+
+func TestParseRegion(t *testing.T) {
+	for text, want := range map[string]Region{
+		"North America":                      RegionNorthAmerica,
+		"north america":                      RegionNorthAmerica,
+		"Europe Qualifier #2":                RegionEurope,
+		"[[../NA Qualifier 1|NA Q1]]":        RegionNorthAmerica,
+		"Season 5/Europe":                    RegionEurope,
+		"Asia-Pacific North":                 RegionAsiaPacificNorth,
+		"APAC-S":                             RegionAsiaPacificSouth,
+		"Asia":                               RegionAsiaPacific,
+		"Middle East & North Africa":         RegionMiddleEast,
+		"SAM Regional":                       RegionSouthAmerica,
+		"":                                   RegionNone,
+		"Na'Vi":                              RegionNone,
+		"Natus Vincere":                      RegionNone,
+		"Sam's Cup":                          RegionNone,
+		"sam":                                RegionNone,
+		"Team Eunited":                       RegionNone,
+		"na":                                 RegionNone,
+		"Asia's Finest":                      RegionNone,
+		"Fall/Europe/Regional 1":             RegionEurope,
+		"Rocket League Oceanic Invitational": RegionNone,
+	} {
+		if got := ParseRegion(text); got != want {
+			t.Errorf("ParseRegion(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestRegionNames(t *testing.T) {
+	for _, info := range regions {
+		if got, err := info.region.MarshalText(); err != nil || string(got) != info.code {
+			t.Errorf("%v.MarshalText() = %s, %v", info.name, got, err)
+		}
+		// Every region named in text comes back from its own name
+		if info.aliases != nil && ParseRegion(info.region.String()) != info.region {
+			t.Errorf("ParseRegion(%q) = %v", info.region.String(), ParseRegion(info.region.String()))
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package rlesports
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/* Recording and replaying of API traffic as fixture files */
+
+// ErrNoFixture is returned when replaying a request that was never recorded
+var ErrNoFixture = errors.New("no fixture for request")
+
+// fixture is a single recorded request/response pair. The body is kept as a string so that
+// fixtures stay readable (and editable) by hand.
+type fixture struct {
+	Query  string      `json:"query"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// fixturePath names the fixture after the request's query string only, so that a recording can be
+// replayed against any base URL
+func fixturePath(dir string, req *http.Request) (string, string) {
+	query := req.URL.Query().Encode()
+	sum := sha256.Sum256([]byte(query))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), query
+}
+
+// Recorder is an http.RoundTripper that saves every response it passes through into Dir
+type Recorder struct {
+	Dir string
+	// Next makes the actual request; nil means http.DefaultTransport
+	Next http.RoundTripper
+}
+
+// RoundTrip makes the request and records the response
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// Store (and pass on) compressed bodies decoded so that fixtures stay readable
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		body, err = io.ReadAll(gz)
+		if err != nil {
+			return nil, err
+		}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = int64(len(body))
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	path, query := fixturePath(r.Dir, req)
+	// Don't escape &, < and > (which are all over wikitext) so that fixtures stay readable
+	var data bytes.Buffer
+	enc := json.NewEncoder(&data)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	err = enc.Encode(fixture{
+		Query:  query,
+		Status: resp.StatusCode,
+		Header: resp.Header,
+		Body:   string(body),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(r.Dir, fs.FileMode(0755)); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data.Bytes(), fs.FileMode(0644)); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Replayer is an http.RoundTripper that serves responses recorded by a Recorder in Dir and never
+// touches the network
+type Replayer struct {
+	Dir string
+}
+
+// RoundTrip serves the recorded response for req
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	path, query := fixturePath(r.Dir, req)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %v", ErrNoFixture, query)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("reading fixture %v: %w", path, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(f.Body))),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package rlesports
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// replayGolden is everything saved by a full update
+type replayGolden struct {
+	Tournaments      []Tournament      `json:"tournaments"`
+	PlayerNames      map[string]string `json:"playerNames"`
+	ProcessedPlayers []string          `json:"processedPlayers"`
+}
+
+// newReplayClient serves responses from the fixtures in testdata/replay/fixtures only
+func newReplayClient(t *testing.T) *LiquipediaClient {
+	t.Helper()
+	dir, err := filepath.Abs(filepath.Join("testdata", "replay", "fixtures"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewLiquipediaClient()
+	client.HTTPClient = &http.Client{Transport: &Replayer{Dir: dir}}
+	client.Limiter = nil
+	return client
+}
+
+// TestReplayUpdate runs a full tournament and player update against recorded API traffic and
+// compares everything saved against testdata/replay/update.golden.json
+func TestReplayUpdate(t *testing.T) {
+	golden, err := filepath.Abs(filepath.Join("testdata", "replay", "update.golden.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newReplayClient(t)
+	inTempDir(t)
+	ctx := context.Background()
+	var storage JsonStorage
+
+	series := []Series{&SeriesConfig{
+		SeriesName: RlcsSeriesName,
+		SeriesTier: "S-Tier",
+		Pattern:    "Rocket League Championship Series/Season {season}/North America/Qualifier {event}",
+		Events:     []Event{{Season: "1", Event: "1"}},
+	}}
+	if err := UpdateTournaments(ctx, client, storage, series, 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdatePlayerNames(ctx, client, storage); err != nil {
+		t.Fatal(err)
+	}
+
+	var g replayGolden
+	if g.Tournaments, err = storage.GetAllTournaments(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if g.PlayerNames, err = storage.GetPlayerNames(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if g.ProcessedPlayers, err = storage.GetProcessedPlayers(ctx); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(g.ProcessedPlayers)
+
+	compareGolden(t, golden, g)
+
+	// Nothing has changed, so a second run is answered by the revision check alone
+	requests := 0
+	client.OnRequest = func(RequestStats) { requests++ }
+	if err := UpdateTournaments(ctx, client, storage, series, 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("second run made %d requests", requests)
+	}
+}
+
+func TestReplayNoFixture(t *testing.T) {
+	client := newReplayClient(t)
+	client.MaxRetries = 3
+
+	var events []ProgressEvent
+	client.Progress = func(e ProgressEvent) { events = append(events, e) }
+
+	if _, err := client.FetchSection(context.Background(), "Never recorded", 0); !errors.Is(err, ErrNoFixture) {
+		t.Errorf("got %v, want ErrNoFixture", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("retried a missing fixture: %+v", events)
+	}
+}
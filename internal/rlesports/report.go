@@ -0,0 +1,41 @@
+package rlesports
+
+import (
+	"fmt"
+	"io"
+)
+
+// UpdateReport tallies a run of updates, so that one failure doesn't hide in the log of a long run
+type UpdateReport struct {
+	// What was updated, e.g. "tournaments"
+	Kind      string
+	Succeeded int
+	Failures  []UpdateFailure
+}
+
+// UpdateFailure is something that failed to update, and why
+type UpdateFailure struct {
+	Name string
+	Err  error
+}
+
+// Fail records a failure
+func (r *UpdateReport) Fail(name string, err error) {
+	r.Failures = append(r.Failures, UpdateFailure{Name: name, Err: err})
+}
+
+// Summary writes the totals followed by every failure to w
+func (r *UpdateReport) Summary(w io.Writer) {
+	fmt.Fprintf(w, "%d %v updated, %d failed\n", r.Succeeded, r.Kind, len(r.Failures))
+	for _, f := range r.Failures {
+		fmt.Fprintf(w, "  %v: %v\n", f.Name, f.Err)
+	}
+}
+
+// Err is an error if anything failed
+func (r *UpdateReport) Err() error {
+	if len(r.Failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d %v failed to update", len(r.Failures), r.Succeeded+len(r.Failures), r.Kind)
+}
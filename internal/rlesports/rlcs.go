@@ -1,120 +1,261 @@
 package rlesports
 
 import (
+	"encoding/json"
 	"fmt"
-	"strconv"
+	"os"
+	"strings"
 )
 
-// RLCS only.
-const prefix = "Rocket League Championship Series/Season "
-
-// Builds a skeleton of all RLCS seasons with tournament names only. These tournament names are then
-// used to query the corresponding page on Liquipedia to fetch all of the further content (teams,
-// players, logos, etc.). At the moment this is manual, but this could potentially be fetched from
-// a particular Liquipedia page (such as "Rocket_League_Championship_Series").
-func buildSeasonSkeletons() []RlcsSeason {
-	var seasons []RlcsSeason
-	const seasonMax = 9
-
-	regions := []Region{RegionNorthAmerica, RegionEurope}
-	for season := 1; season <= seasonMax; season++ {
-		rlcsSeason := RlcsSeason{Season: strconv.Itoa(season)}
-
-		if season == 1 {
-			// Two qualifiers for S1
-			for qualifier := 1; qualifier <= 2; qualifier++ {
-				section := Section{Name: fmt.Sprintf("Qualifier %d", qualifier)}
-
-				for _, region := range regions {
-					section.Tournaments = append(section.Tournaments, Tournament{
-						Region: region,
-						Name:   fmt.Sprintf("%s%d/%s/Qualifier %d", prefix, season, region.String(), qualifier),
-					})
+// Skeletons of all RLCS seasons with tournament names only. These tournament names are then used
+// to query the corresponding page on Liquipedia to fetch all of the further content (teams,
+// players, logos, etc.). The seasons live in a JSON file, so adding an event is a data change.
+const skeletonsFilename = "src/data/skeletons.json"
+
+// Placeholders in titles: regionPlaceholder is replaced with each of the tournament's regions in
+// turn, and splitPlaceholder with the name of the split the tournament is in
+const (
+	regionPlaceholder = "{region}"
+	splitPlaceholder  = "{split}"
+)
+
+// SkeletonConfig describes every season we track, in order
+type SkeletonConfig struct {
+	Seasons []SeasonConfig `json:"seasons"`
+}
+
+// SeasonConfig is a season and its sections, e.g. qualifiers, regionals and finals. From RLCS X
+// onwards seasons are made of splits, and only season-wide events like Worlds are sections, which
+// come after the splits.
+type SeasonConfig struct {
+	Season   string          `json:"season"`
+	Splits   []SplitConfig   `json:"splits,omitempty"`
+	Sections []SectionConfig `json:"sections"`
+}
+
+// SplitConfig is a split of a season, e.g. "Fall", with its regional events and its Major
+type SplitConfig struct {
+	Name     string          `json:"name"`
+	Sections []SectionConfig `json:"sections"`
+	Major    *SectionConfig  `json:"major,omitempty"`
+}
+
+// SectionConfig is a group of tournaments within a season
+type SectionConfig struct {
+	Name        string             `json:"name"`
+	Tournaments []TournamentConfig `json:"tournaments"`
+}
+
+// TournamentConfig is a Liquipedia page, or one per region if Title contains "{region}", e.g.
+// "Rocket League Championship Series/Season 2/{region}" with regions ["na", "eu"]. Within a split,
+// "{split}" is the split's name. Tournaments without regions (e.g. LANs) get theirs from their
+// teams.
+type TournamentConfig struct {
+	Title   string   `json:"title"`
+	Regions []Region `json:"regions,omitempty"`
+}
+
+// LoadSkeletonConfig reads and validates a skeleton config file
+func LoadSkeletonConfig(filename string) (*SkeletonConfig, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var config SkeletonConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%v: %w", filename, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("%v: %w", filename, err)
+	}
+	return &config, nil
+}
+
+// Validate checks that every season, split, section and tournament is named, that regions are
+// real regions, and that no page is listed twice
+func (c *SkeletonConfig) Validate() error {
+	if len(c.Seasons) == 0 {
+		return fmt.Errorf("no seasons")
+	}
+
+	seasons := make(map[string]bool)
+	titles := make(map[string]bool)
+	validateSection := func(where string, section SectionConfig, season, split string) error {
+		if section.Name == "" {
+			return fmt.Errorf("%v: section with no name", where)
+		}
+		for _, tournament := range section.Tournaments {
+			if err := tournament.validate(split != ""); err != nil {
+				return fmt.Errorf("%v, %v: %w", where, section.Name, err)
+			}
+			for _, t := range tournament.expand(season, split) {
+				if titles[t.Name] {
+					return fmt.Errorf("%v listed twice", t.Name)
 				}
-				rlcsSeason.Sections = append(rlcsSeason.Sections, section)
+				titles[t.Name] = true
+			}
+		}
+		return nil
+	}
+
+	for _, season := range c.Seasons {
+		if season.Season == "" {
+			return fmt.Errorf("season with no name")
+		}
+		if seasons[season.Season] {
+			return fmt.Errorf("season %v listed twice", season.Season)
+		}
+		seasons[season.Season] = true
+
+		splits := make(map[string]bool)
+		for _, split := range season.Splits {
+			if split.Name == "" {
+				return fmt.Errorf("season %v: split with no name", season.Season)
 			}
-		} else {
-			section := Section{Name: "Regional"}
-			for _, region := range regions {
-				section.Tournaments = append(section.Tournaments, Tournament{Region: region, Name: fmt.Sprintf("%s%d/%s", prefix, season, region.String())})
+			if splits[split.Name] {
+				return fmt.Errorf("season %v: split %v listed twice", season.Season, split.Name)
 			}
+			splits[split.Name] = true
 
-			// Season 3 onwards: OCE
-			if season >= 3 {
-				if season == 3 {
-					section.Tournaments = append(section.Tournaments, Tournament{
-						Region: RegionOceania,
-						Name:   "ThrowdownTV/Rocket League Challenge/Season 2/League Play",
-					})
-				} else if season >= 4 {
-					section.Tournaments = append(section.Tournaments, Tournament{
-						Region: RegionOceania,
-						Name:   fmt.Sprintf("%s%d/%s/League Play", prefix, season, RegionOceania.String()),
-					})
+			where := fmt.Sprintf("season %v, %v", season.Season, split.Name)
+			for _, section := range split.Sections {
+				if err := validateSection(where, section, season.Season, split.Name); err != nil {
+					return err
 				}
 			}
+			if split.Major != nil {
+				if err := validateSection(where, *split.Major, season.Season, split.Name); err != nil {
+					return err
+				}
+			}
+		}
 
-			// TODO: SAM and MENA
-
-			rlcsSeason.Sections = append(rlcsSeason.Sections, section)
+		for _, section := range season.Sections {
+			if err := validateSection("season "+season.Season, section, season.Season, ""); err != nil {
+				return err
+			}
 		}
-		// COVID :(
-		if season != 9 {
-			rlcsSeason.Sections = append(rlcsSeason.Sections,
-				Section{
-					Name:        "Finals",
-					Tournaments: []Tournament{{Region: RegionWorld, Name: fmt.Sprintf("%s%d", prefix, season)}},
-				},
-			)
+	}
+	return nil
+}
+
+func (tc TournamentConfig) validate(inSplit bool) error {
+	if strings.TrimSpace(tc.Title) == "" {
+		return fmt.Errorf("tournament with no title")
+	}
+	for _, region := range tc.Regions {
+		if region == RegionNone || region == RegionWorld {
+			return fmt.Errorf("%v: %q isn't a region teams come from", tc.Title, region.Code())
 		}
+	}
+	if strings.Contains(tc.Title, regionPlaceholder) && len(tc.Regions) == 0 {
+		return fmt.Errorf("%v: %v without any regions", tc.Title, regionPlaceholder)
+	}
+	if strings.Contains(tc.Title, splitPlaceholder) && !inSplit {
+		return fmt.Errorf("%v: %v outside of a split", tc.Title, splitPlaceholder)
+	}
+	rest := strings.NewReplacer(regionPlaceholder, "", splitPlaceholder, "").Replace(tc.Title)
+	if strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("%v: unknown placeholder", tc.Title)
+	}
+	return nil
+}
 
-		seasons = append(seasons, rlcsSeason)
+// expand turns the config into tournaments, one per region if the title has a placeholder
+func (tc TournamentConfig) expand(season, split string) []Tournament {
+	title := strings.ReplaceAll(tc.Title, splitPlaceholder, split)
+	if !strings.Contains(title, regionPlaceholder) {
+		return []Tournament{{Name: title, Season: season, Regions: tc.Regions}}
 	}
 
-	return seasons
+	tournaments := make([]Tournament, 0, len(tc.Regions))
+	for _, region := range tc.Regions {
+		tournaments = append(tournaments, Tournament{
+			Name:    strings.ReplaceAll(title, regionPlaceholder, region.String()),
+			Season:  season,
+			Regions: []Region{region},
+		})
+	}
+	return tournaments
 }
 
-var SeasonSkeletons = buildSeasonSkeletons()
-
-func TournamentSkeletons(maxSeason int) (tournaments []Tournament) {
-	getRegions := func(season int) []Region {
-		if season < 3 {
-			return []Region{RegionNorthAmerica, RegionEurope}
-		} else {
-			return []Region{RegionNorthAmerica, RegionEurope, RegionOceania}
-		}
+// section expands a section's tournaments
+func (sc SectionConfig) section(season, split string) Section {
+	section := Section{Name: sc.Name}
+	for _, tournament := range sc.Tournaments {
+		section.Tournaments = append(section.Tournaments, tournament.expand(season, split)...)
 	}
+	return section
+}
 
-	for season := 1; season <= maxSeason; season++ {
-		if season == 1 {
-			// Regionals: Two qualifiers for S1
-			for qualifier := 1; qualifier <= 2; qualifier++ {
-				for _, region := range getRegions(season) {
-					tournaments = append(tournaments, Tournament{
-						Name:   fmt.Sprintf("%s%d/%s/Qualifier %d", prefix, season, region.String(), qualifier),
-						Region: region,
-						Season: strconv.Itoa(season),
-					})
-				}
+// SeasonSkeletons are the configured seasons, broken into splits and sections
+func (c *SkeletonConfig) SeasonSkeletons() []RlcsSeason {
+	seasons := make([]RlcsSeason, 0, len(c.Seasons))
+	for _, season := range c.Seasons {
+		rlcsSeason := RlcsSeason{Season: season.Season}
+		for _, sc := range season.Splits {
+			split := Split{Name: sc.Name}
+			for _, section := range sc.Sections {
+				split.Sections = append(split.Sections, section.section(season.Season, sc.Name))
 			}
-		} else {
-			// Regionals
-			for _, region := range getRegions(season) {
-				tournaments = append(tournaments, Tournament{
-					Name:   fmt.Sprintf("%s%d/%s", prefix, season, region.String()),
-					Region: region,
-					Season: strconv.Itoa(season),
-				})
+			if sc.Major != nil {
+				major := sc.Major.section(season.Season, sc.Name)
+				split.Major = &major
 			}
+			rlcsSeason.Splits = append(rlcsSeason.Splits, split)
 		}
+		for _, section := range season.Sections {
+			rlcsSeason.Sections = append(rlcsSeason.Sections, section.section(season.Season, ""))
+		}
+		seasons = append(seasons, rlcsSeason)
+	}
+	return seasons
+}
 
-		// Finals
-		tournaments = append(tournaments, Tournament{
-			Name:   fmt.Sprintf("%s%d", prefix, season),
-			Region: RegionWorld,
-			Season: strconv.Itoa(season),
-		})
+// Tournaments are all of the season's tournaments in order: each split's sections then its Major,
+// then the season-wide sections
+func (s RlcsSeason) Tournaments() (tournaments []Tournament) {
+	for _, split := range s.Splits {
+		for _, section := range split.Sections {
+			tournaments = append(tournaments, section.Tournaments...)
+		}
+		if split.Major != nil {
+			tournaments = append(tournaments, split.Major.Tournaments...)
+		}
 	}
+	for _, section := range s.Sections {
+		tournaments = append(tournaments, section.Tournaments...)
+	}
+	return tournaments
+}
 
+// TournamentSkeletons are the tournaments of the first maxSeason seasons, in order
+func (c *SkeletonConfig) TournamentSkeletons(maxSeason int) (tournaments []Tournament) {
+	seasons := c.SeasonSkeletons()
+	if maxSeason < len(seasons) {
+		seasons = seasons[:maxSeason]
+	}
+	for _, season := range seasons {
+		tournaments = append(tournaments, season.Tournaments()...)
+	}
 	return tournaments
 }
+
+// SeasonSkeletons loads the seasons from the skeleton config
+func SeasonSkeletons() ([]RlcsSeason, error) {
+	config, err := LoadSkeletonConfig(skeletonsFilename)
+	if err != nil {
+		return nil, err
+	}
+	return config.SeasonSkeletons(), nil
+}
+
+// TournamentSkeletons loads the tournaments of the first maxSeason seasons from the skeleton config
+func TournamentSkeletons(maxSeason int) ([]Tournament, error) {
+	config, err := LoadSkeletonConfig(skeletonsFilename)
+	if err != nil {
+		return nil, err
+	}
+	return config.TournamentSkeletons(maxSeason), nil
+}
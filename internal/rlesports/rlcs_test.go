@@ -0,0 +1,112 @@
+package rlesports
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSkeletonConfigFile(t *testing.T) {
+	config, err := LoadSkeletonConfig(filepath.Join("..", "..", skeletonsFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make(map[string]Tournament)
+	for _, tournament := range config.TournamentSkeletons(len(config.Seasons)) {
+		names[tournament.Name] = tournament
+	}
+
+	// Both views come from the same config, so they have to agree
+	count := 0
+	for _, season := range config.SeasonSkeletons() {
+		for _, tournament := range season.Tournaments() {
+			if _, ok := names[tournament.Name]; !ok {
+				t.Errorf("%v is a season skeleton but not a tournament skeleton", tournament.Name)
+			}
+			count++
+		}
+	}
+	if count != len(names) {
+		t.Errorf("got %d season skeletons, %d tournament skeletons", count, len(names))
+	}
+
+	oce, ok := names["ThrowdownTV/Rocket League Challenge/Season 2/League Play"]
+	if !ok || oce.Season != "3" || len(oce.Regions) != 1 || oce.Regions[0] != RegionOceania {
+		t.Errorf("Season 3 OCE = %+v", oce)
+	}
+	if _, ok := names["Rocket League Championship Series/Season 9"]; ok {
+		t.Error("Season 9 had no finals")
+	}
+	if len(config.TournamentSkeletons(1)) != 5 {
+		t.Errorf("Season 1 has %d tournaments, want 5", len(config.TournamentSkeletons(1)))
+	}
+
+	// Splits expand {split}, and their Majors come before the next split's regionals
+	for _, name := range []string{
+		"Rocket League Championship Series/Season X/Fall/North America/Regional 1",
+		"Rocket League Championship Series/Season X/Spring/Major",
+		"Rocket League Championship Series/2021-22/Winter/Asia-Pacific South/Regional 3",
+		"Rocket League Championship Series/2022-23/Spring/Asia-Pacific/Invitational",
+		"Rocket League Championship Series/2022-23/World Championship",
+	} {
+		if _, ok := names[name]; !ok {
+			t.Errorf("missing %v", name)
+		}
+	}
+	for _, season := range config.SeasonSkeletons() {
+		if season.Season != "2021-22" {
+			continue
+		}
+		if len(season.Splits) != 3 || season.Splits[0].Major == nil {
+			t.Fatalf("2021-22 splits = %+v", season.Splits)
+		}
+		tournaments := season.Tournaments()
+		fallMajor := season.Splits[0].Major.Tournaments[0].Name
+		winterFirst := season.Splits[1].Sections[0].Tournaments[0].Name
+		majorIdx, winterIdx := -1, -1
+		for i, tournament := range tournaments {
+			switch tournament.Name {
+			case fallMajor:
+				majorIdx = i
+			case winterFirst:
+				winterIdx = i
+			}
+		}
+		if majorIdx < 0 || majorIdx > winterIdx {
+			t.Errorf("Fall Major at %d, Winter regionals from %d", majorIdx, winterIdx)
+		}
+		if last := tournaments[len(tournaments)-1]; last.Name != "Rocket League Championship Series/2021-22/World Championship" {
+			t.Errorf("2021-22 ends with %v", last.Name)
+		}
+	}
+}
+
+func TestSkeletonConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		config string
+		err    string
+	}{
+		{`{"seasons":[]}`, "no seasons"},
+		{`{"seasons":[{"season":"1"},{"season":"1"}]}`, "listed twice"},
+		{`{"seasons":[{"season":"1","sections":[{"tournaments":[]}]}]}`, "section with no name"},
+		{`{"seasons":[{"season":"1","sections":[{"name":"A","tournaments":[{"title":""}]}]}]}`, "no title"},
+		{`{"seasons":[{"season":"1","sections":[{"name":"A","tournaments":[{"title":"X/{region}"}]}]}]}`, "without any regions"},
+		{`{"seasons":[{"season":"1","sections":[{"name":"A","tournaments":[{"title":"X/{season}","regions":["na"]}]}]}]}`, "unknown placeholder"},
+		{`{"seasons":[{"season":"1","sections":[{"name":"A","tournaments":[{"title":"X","regions":["world"]}]}]}]}`, "isn't a region"},
+		{`{"seasons":[{"season":"1","sections":[{"name":"A","tournaments":[{"title":"X/{region}","regions":["na"]},{"title":"X/North America"}]}]}]}`, "listed twice"},
+		{`{"seasons":[{"season":"1","sections":[{"name":"A","tournaments":[{"title":"X/{split}"}]}]}]}`, "outside of a split"},
+		{`{"seasons":[{"season":"1","splits":[{"name":"Fall"},{"name":"Fall"}]}]}`, "split Fall listed twice"},
+		{`{"seasons":[{"season":"1","splits":[{"sections":[]}]}]}`, "split with no name"},
+		{`{"seasons":[{"season":"1","splits":[{"name":"Fall","major":{"name":"Major","tournaments":[{"title":"X/{split}/Major"}]}},{"name":"Winter","sections":[{"name":"A","tournaments":[{"title":"X/Fall/Major"}]}]}]}]}`, "listed twice"},
+	} {
+		var config SkeletonConfig
+		if err := json.Unmarshal([]byte(tc.config), &config); err != nil {
+			t.Fatal(err)
+		}
+		if err := config.Validate(); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%v: got %v, want %q", tc.config, err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package rlesports
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+/* Tournament series, RLCS or otherwise. Each series knows how its Liquipedia pages are named and
+how highly they're tiered, so that updates and discovery can cover third-party events too. */
+
+// seriesFilename lists the non-RLCS series we track
+const seriesFilename = "src/data/series.json"
+
+// RlcsSeriesName is the name the RLCS is registered under
+const RlcsSeriesName = "RLCS"
+
+// Placeholders in series patterns, on top of regionPlaceholder
+const (
+	seasonPlaceholder = "{season}"
+	eventPlaceholder  = "{event}"
+)
+
+// Series is a run of tournaments that share a naming scheme on Liquipedia, e.g. the RLCS or the
+// DreamHack Pro Circuit
+type Series interface {
+	// Name identifies the series, e.g. "RLCS"
+	Name() string
+	// Tier is Liquipedia's tier for the series' events, e.g. "S-Tier"
+	Tier() string
+	// Skeletons are the tournaments of the series' first maxSeasons seasons, in order, with names
+	// only. They have Series and Tier filled in.
+	Skeletons(maxSeasons int) ([]Tournament, error)
+	// Match reports whether a Liquipedia page, or a subpage of one, belongs to the series, and
+	// which season it's from
+	Match(title string) (season string, ok bool)
+}
+
+var (
+	registeredSeries = make(map[string]Series)
+	seriesOrder      []string
+)
+
+func init() {
+	if err := RegisterSeries(rlcsSeries{}); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterSeries adds a series to those updated and discovered. Names must be unique.
+func RegisterSeries(series Series) error {
+	if _, ok := registeredSeries[series.Name()]; ok {
+		return fmt.Errorf("series %v registered twice", series.Name())
+	}
+	registeredSeries[series.Name()] = series
+	seriesOrder = append(seriesOrder, series.Name())
+	return nil
+}
+
+// RegisteredSeries are all registered series, in the order they were registered
+func RegisteredSeries() []Series {
+	series := make([]Series, 0, len(seriesOrder))
+	for _, name := range seriesOrder {
+		series = append(series, registeredSeries[name])
+	}
+	return series
+}
+
+// LookupSeries finds a registered series by name
+func LookupSeries(name string) (Series, bool) {
+	series, ok := registeredSeries[name]
+	return series, ok
+}
+
+// MatchSeries finds the registered series a page belongs to, and its season
+func MatchSeries(title string) (series Series, season string, ok bool) {
+	for _, s := range RegisteredSeries() {
+		if season, ok := s.Match(title); ok {
+			return s, season, true
+		}
+	}
+	return nil, "", false
+}
+
+// rlcsSeries is the RLCS, whose seasons come from the skeleton config
+type rlcsSeries struct{}
+
+func (rlcsSeries) Name() string { return RlcsSeriesName }
+
+func (rlcsSeries) Tier() string { return "S-Tier" }
+
+func (s rlcsSeries) Skeletons(maxSeasons int) ([]Tournament, error) {
+	tournaments, err := TournamentSkeletons(maxSeasons)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tournaments {
+		tournaments[i].Series = s.Name()
+		tournaments[i].Tier = s.Tier()
+	}
+	return tournaments, nil
+}
+
+func (rlcsSeries) Match(title string) (string, bool) {
+	if !strings.HasPrefix(title, RlcsPortalTitle+"/") {
+		return "", false
+	}
+	for _, segment := range strings.Split(title, "/")[1:] {
+		if res := seasonSegmentRegex.FindStringSubmatch(segment); res != nil {
+			return res[1] + res[2], true
+		}
+	}
+	return "", true
+}
+
+// SeriesConfigFile lists non-RLCS series
+type SeriesConfigFile struct {
+	Series []SeriesConfig `json:"series"`
+}
+
+// SeriesConfig is a series whose pages are all named after Pattern, e.g.
+// "Gfinity/Elite Series/Season {season}" or "DreamHack/{season}/{event}". A pattern with
+// "{region}" gives one page per region.
+type SeriesConfig struct {
+	SeriesName string   `json:"name"`
+	SeriesTier string   `json:"tier"`
+	Pattern    string   `json:"pattern"`
+	Regions    []Region `json:"regions,omitempty"`
+	Events     []Event  `json:"events"`
+
+	match *regexp.Regexp
+}
+
+// Event is one of a series' tournaments, in order. Events can have their own tier and regions,
+// e.g. a Major in a circuit of Opens.
+type Event struct {
+	Season  string   `json:"season"`
+	Event   string   `json:"event,omitempty"`
+	Tier    string   `json:"tier,omitempty"`
+	Regions []Region `json:"regions,omitempty"`
+}
+
+// LoadSeriesConfig reads and validates a series config file
+func LoadSeriesConfig(filename string) ([]*SeriesConfig, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var config SeriesConfigFile
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%v: %w", filename, err)
+	}
+
+	series := make([]*SeriesConfig, 0, len(config.Series))
+	for i := range config.Series {
+		sc := &config.Series[i]
+		if err := sc.Validate(); err != nil {
+			return nil, fmt.Errorf("%v: %w", filename, err)
+		}
+		series = append(series, sc)
+	}
+	return series, nil
+}
+
+// RegisterConfiguredSeries registers every series in the series config. Without a config only the
+// RLCS is registered.
+func RegisterConfiguredSeries() error {
+	series, err := LoadSeriesConfig(seriesFilename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, s := range series {
+		if err := RegisterSeries(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks that the series is named, that its pattern only has known placeholders that its
+// events fill in, and that no page is listed twice
+func (sc *SeriesConfig) Validate() error {
+	if sc.SeriesName == "" {
+		return fmt.Errorf("series with no name")
+	}
+	if sc.Pattern == "" {
+		return fmt.Errorf("%v: no pattern", sc.SeriesName)
+	}
+	rest := strings.NewReplacer(seasonPlaceholder, "", eventPlaceholder, "", regionPlaceholder, "").Replace(sc.Pattern)
+	if strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("%v: unknown placeholder in %v", sc.SeriesName, sc.Pattern)
+	}
+	if len(sc.Events) == 0 {
+		return fmt.Errorf("%v: no events", sc.SeriesName)
+	}
+
+	titles := make(map[string]bool)
+	for _, event := range sc.Events {
+		if event.Season == "" {
+			return fmt.Errorf("%v: event with no season", sc.SeriesName)
+		}
+		if strings.Contains(sc.Pattern, eventPlaceholder) && event.Event == "" {
+			return fmt.Errorf("%v, %v: %v with no event", sc.SeriesName, event.Season, eventPlaceholder)
+		}
+		tc := sc.tournament(event)
+		if err := tc.validate(false); err != nil {
+			return fmt.Errorf("%v: %w", sc.SeriesName, err)
+		}
+		for _, t := range tc.expand(event.Season, "") {
+			if titles[t.Name] {
+				return fmt.Errorf("%v: %v listed twice", sc.SeriesName, t.Name)
+			}
+			titles[t.Name] = true
+		}
+	}
+	return nil
+}
+
+// tournament fills the pattern in for an event, leaving regions to expand
+func (sc *SeriesConfig) tournament(event Event) TournamentConfig {
+	regions := event.Regions
+	if len(regions) == 0 {
+		regions = sc.Regions
+	}
+	return TournamentConfig{
+		Title:   strings.NewReplacer(seasonPlaceholder, event.Season, eventPlaceholder, event.Event).Replace(sc.Pattern),
+		Regions: regions,
+	}
+}
+
+func (sc *SeriesConfig) Name() string { return sc.SeriesName }
+
+func (sc *SeriesConfig) Tier() string { return sc.SeriesTier }
+
+func (sc *SeriesConfig) Skeletons(maxSeasons int) ([]Tournament, error) {
+	var tournaments []Tournament
+	seasons := make(map[string]bool)
+	for _, event := range sc.Events {
+		if !seasons[event.Season] {
+			if len(seasons) == maxSeasons {
+				break
+			}
+			seasons[event.Season] = true
+		}
+
+		tier := event.Tier
+		if tier == "" {
+			tier = sc.SeriesTier
+		}
+		for _, t := range sc.tournament(event).expand(event.Season, "") {
+			t.Series = sc.SeriesName
+			t.Tier = tier
+			tournaments = append(tournaments, t)
+		}
+	}
+	return tournaments, nil
+}
+
+// Match turns the pattern into a regular expression, with each placeholder matching a single path
+// segment
+func (sc *SeriesConfig) Match(title string) (string, bool) {
+	if sc.match == nil {
+		pattern := regexp.QuoteMeta(sc.Pattern)
+		for _, placeholder := range []string{seasonPlaceholder, eventPlaceholder, regionPlaceholder} {
+			group := `[^/]+`
+			if placeholder == seasonPlaceholder {
+				group = `(?P<season>[^/]+)`
+			}
+			pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta(placeholder), group)
+		}
+		sc.match = regexp.MustCompile(`^` + pattern + `(?:/.*)?$`)
+	}
+
+	res := sc.match.FindStringSubmatch(title)
+	if res == nil {
+		return "", false
+	}
+	if i := sc.match.SubexpIndex("season"); i >= 0 {
+		return res[i], true
+	}
+	// One-off events say which season they're from
+	for _, event := range sc.Events {
+		if tc := sc.tournament(event); title == tc.Title || strings.HasPrefix(title, tc.Title+"/") {
+			return event.Season, true
+		}
+	}
+	return "", true
+}
+
+// SeriesNames lists the names of all registered series, sorted
+func SeriesNames() []string {
+	names := append([]string(nil), seriesOrder...)
+	sort.Strings(names)
+	return names
+}
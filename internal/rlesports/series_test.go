@@ -0,0 +1,203 @@
+package rlesports
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSeriesConfigFile(t *testing.T) {
+	series, err := LoadSeriesConfig(filepath.Join("..", "..", seriesFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range series {
+		if s.Name() == RlcsSeriesName {
+			t.Errorf("%v is registered by default", RlcsSeriesName)
+		}
+		skeletons, err := s.Skeletons(len(s.Events))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(skeletons) == 0 {
+			t.Errorf("%v has no tournaments", s.Name())
+		}
+		// Every page the series lists has to be recognised as one of its own
+		for _, tournament := range skeletons {
+			season, ok := s.Match(tournament.Name)
+			if !ok || season != tournament.Season || tournament.Series != s.Name() || tournament.Tier == "" {
+				t.Errorf("%v: got %+v, matched season %q %v", s.Name(), tournament, season, ok)
+			}
+		}
+	}
+}
+
+// TestConfiguredSeries checks each series in the series config names the pages it should, and
+// recognises their subpages
+func TestConfiguredSeries(t *testing.T) {
+	series, err := LoadSeriesConfig(filepath.Join("..", "..", seriesFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		tier    string
+		titles  []string
+		subpage string
+		season  string
+	}{
+		{
+			name:    "DreamHack Pro Circuit",
+			tier:    "A-Tier",
+			titles:  []string{"DreamHack/2019/Leipzig", "DreamHack/2019/Montreal", "DreamHack/2020/Leipzig", "DreamHack/2020/Anaheim"},
+			subpage: "DreamHack/2020/Anaheim/Playoffs",
+			season:  "2020",
+		},
+		{
+			name:    "Gfinity Elite Series",
+			tier:    "B-Tier",
+			titles:  []string{"Gfinity/Elite Series/Season 1", "Gfinity/Elite Series/Season 2"},
+			subpage: "Gfinity/Elite Series/Season 2/Playoffs",
+			season:  "2",
+		},
+		{
+			name:    "NRG Invitational",
+			tier:    "B-Tier",
+			titles:  []string{"NRG Invitational"},
+			subpage: "NRG Invitational/Qualifier",
+			season:  "2018",
+		},
+	} {
+		var s *SeriesConfig
+		for _, configured := range series {
+			if configured.Name() == tc.name {
+				s = configured
+			}
+		}
+		if s == nil {
+			t.Errorf("%v isn't configured", tc.name)
+			continue
+		}
+
+		skeletons, err := s.Skeletons(len(s.Events))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var titles []string
+		for _, tournament := range skeletons {
+			titles = append(titles, tournament.Name)
+			if tournament.Tier != tc.tier {
+				t.Errorf("%v: tier %v", tournament.Name, tournament.Tier)
+			}
+		}
+		if !reflect.DeepEqual(titles, tc.titles) {
+			t.Errorf("%v: titles %q", tc.name, titles)
+		}
+
+		if season, ok := s.Match(tc.subpage); !ok || season != tc.season {
+			t.Errorf("%v: Match(%v) = %q, %v", tc.name, tc.subpage, season, ok)
+		}
+		// Nor does a series claim any other's pages
+		for _, other := range series {
+			if other == s {
+				continue
+			}
+			if _, ok := other.Match(tc.titles[0]); ok {
+				t.Errorf("%v matches %v", other.Name(), tc.titles[0])
+			}
+		}
+	}
+}
+
+func TestSeriesConfig(t *testing.T) {
+	dreamhack := &SeriesConfig{
+		SeriesName: "DreamHack Pro Circuit",
+		SeriesTier: "A-Tier",
+		Pattern:    "DreamHack/{season}/{event}",
+		Events: []Event{
+			{Season: "2019", Event: "Leipzig"},
+			{Season: "2019", Event: "Montreal", Tier: "B-Tier", Regions: []Region{RegionNorthAmerica}},
+			{Season: "2020", Event: "Leipzig"},
+		},
+	}
+	if err := dreamhack.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	skeletons, _ := dreamhack.Skeletons(1)
+	if len(skeletons) != 2 || skeletons[1].Name != "DreamHack/2019/Montreal" || skeletons[1].Tier != "B-Tier" ||
+		len(skeletons[1].Regions) != 1 || skeletons[0].Tier != "A-Tier" {
+		t.Errorf("first season = %+v", skeletons)
+	}
+
+	for title, want := range map[string]string{
+		"DreamHack/2020/Leipzig":         "2020",
+		"DreamHack/2020/Leipzig/Bracket": "2020",
+		"DreamHack/2020":                 "",
+		"DreamHack Open/2020/Leipzig":    "",
+	} {
+		season, ok := dreamhack.Match(title)
+		if season != want || ok != (want != "") {
+			t.Errorf("Match(%v) = %q, %v", title, season, ok)
+		}
+	}
+
+	// One-off events get their season from the config
+	nrg := &SeriesConfig{SeriesName: "NRG Invitational", Pattern: "NRG Invitational", Events: []Event{{Season: "2018"}}}
+	if season, ok := nrg.Match("NRG Invitational"); !ok || season != "2018" {
+		t.Errorf("NRG Invitational = %q, %v", season, ok)
+	}
+
+	if season, ok := (rlcsSeries{}).Match("Rocket League Championship Series/Season X/Fall/Europe/Regional 1"); !ok || season != "X" {
+		t.Errorf("RLCS X = %q, %v", season, ok)
+	}
+}
+
+func TestSeriesConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		config string
+		err    string
+	}{
+		{`{"pattern":"A"}`, "no name"},
+		{`{"name":"A","events":[{"season":"1"}]}`, "no pattern"},
+		{`{"name":"A","pattern":"A/{split}","events":[{"season":"1"}]}`, "unknown placeholder"},
+		{`{"name":"A","pattern":"A/{season}"}`, "no events"},
+		{`{"name":"A","pattern":"A/{season}","events":[{}]}`, "no season"},
+		{`{"name":"A","pattern":"A/{event}","events":[{"season":"1"}]}`, "with no event"},
+		{`{"name":"A","pattern":"A/{region}","events":[{"season":"1"}]}`, "without any regions"},
+		{`{"name":"A","pattern":"A","events":[{"season":"1"},{"season":"2"}]}`, "listed twice"},
+	} {
+		var config SeriesConfig
+		if err := json.Unmarshal([]byte(tc.config), &config); err != nil {
+			t.Fatal(err)
+		}
+		if err := config.Validate(); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%v: got %v, want %q", tc.config, err, tc.err)
+		}
+	}
+}
+
+func TestRegisterConfiguredSeriesMissing(t *testing.T) {
+	inTempDir(t)
+	before := SeriesNames()
+
+	// No config is just the RLCS
+	if err := RegisterConfiguredSeries(); err != nil {
+		t.Errorf("no config: %v", err)
+	}
+	if got := SeriesNames(); !reflect.DeepEqual(got, before) {
+		t.Errorf("registered %v", got)
+	}
+
+	if err := os.WriteFile(seriesFilename, []byte(`{"series": [`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterConfiguredSeries(); err == nil {
+		t.Error("no error for a broken config")
+	}
+}
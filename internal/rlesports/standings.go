@@ -0,0 +1,158 @@
+package rlesports
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/* Group stage and league standings */
+
+var crosstableResultRegex = regexp.MustCompile(`^result([0-9]+)vs([0-9]+)$`)
+
+// ParseGroupTables reads group/league standings, from either
+// {{GroupTableStart|Group A}} {{GroupTableSlot|{{Team|nrg}}|place=1|win_m=7|lose_m=0|win_g=21|lose_g=4|diff=+17}} {{GroupTableEnd}}
+// or crosstables, {{Crosstable|title=|team1=A|team2=B|result1vs2=3-1|...}}
+func ParseGroupTables(wikitext string) []GroupTable {
+	tables := []GroupTable{}
+
+	var current *GroupTable
+	finish := func() {
+		if current != nil && len(current.Standings) > 0 {
+			tables = append(tables, *current)
+		}
+		current = nil
+	}
+
+	ParseWikitext(wikitext).Walk(func(t *Template) {
+		switch {
+		case t.Is("GroupTableStart", "GroupTableLeague"):
+			finish()
+			title := t.Param("title")
+			if title == "" {
+				title = t.Param("1")
+			}
+			current = &GroupTable{Title: title}
+		case t.Is("GroupTableSlot"):
+			if current == nil {
+				current = &GroupTable{}
+			}
+			current.Standings = append(current.Standings, parseGroupTableSlot(t, len(current.Standings)+1))
+		case t.Is("GroupTableEnd"):
+			finish()
+		case t.Is("Crosstable", "CrossTable"):
+			finish()
+			if table := parseCrosstable(t); len(table.Standings) > 0 {
+				tables = append(tables, table)
+			}
+		}
+	})
+	finish()
+
+	return tables
+}
+
+// signReplacer normalizes signed numbers like "+17" and "−7" (with a real minus sign) for Atoi
+var signReplacer = strings.NewReplacer("+", "", "−", "-", "–", "-")
+
+func parseSigned(s string) (int, error) {
+	return strconv.Atoi(signReplacer.Replace(strings.TrimSpace(s)))
+}
+
+func atoiParam(t *Template, name string) int {
+	n, _ := parseSigned(t.Param(name))
+	return n
+}
+
+// parseGroupTableSlot reads a single row of a group table. Rows without a place are numbered in
+// order.
+func parseGroupTableSlot(slot *Template, row int) Standing {
+	team, _ := slot.ParamValue("1")
+	name, _ := parseOpponent(team)
+
+	standing := Standing{
+		Team:        name,
+		Place:       atoiParam(slot, "place"),
+		MatchWins:   atoiParam(slot, "win_m"),
+		MatchLosses: atoiParam(slot, "lose_m"),
+		GameWins:    atoiParam(slot, "win_g"),
+		GameLosses:  atoiParam(slot, "lose_g"),
+	}
+	if standing.Place == 0 {
+		standing.Place = row
+	}
+	if diff, err := parseSigned(slot.Param("diff")); err == nil {
+		standing.GameDiff = diff
+	} else {
+		standing.GameDiff = standing.GameWins - standing.GameLosses
+	}
+	return standing
+}
+
+// parseCrosstable works out standings from every pairing's result. Places are decided by match
+// wins, then game differential. Teams that are level on both share a place.
+func parseCrosstable(crosstable *Template) GroupTable {
+	table := GroupTable{Title: crosstable.Param("title")}
+
+	index := make(map[int]int)
+	for n := 1; ; n++ {
+		value, ok := crosstable.ParamValue("team" + strconv.Itoa(n))
+		if !ok {
+			break
+		}
+		name, _ := parseOpponent(value)
+		index[n] = len(table.Standings)
+		table.Standings = append(table.Standings, Standing{Team: name})
+	}
+
+	for _, param := range crosstable.Params {
+		res := crosstableResultRegex.FindStringSubmatch(param.Name)
+		if res == nil {
+			continue
+		}
+		a, _ := strconv.Atoi(res[1])
+		b, _ := strconv.Atoi(res[2])
+		score := scoreRegex.FindStringSubmatch(param.Value.Text())
+		i, okA := index[a]
+		j, okB := index[b]
+		if score == nil || !okA || !okB {
+			continue
+		}
+		gamesA, _ := strconv.Atoi(score[1])
+		gamesB, _ := strconv.Atoi(score[2])
+
+		table.Standings[i].GameWins += gamesA
+		table.Standings[i].GameLosses += gamesB
+		table.Standings[j].GameWins += gamesB
+		table.Standings[j].GameLosses += gamesA
+		if gamesA > gamesB {
+			table.Standings[i].MatchWins++
+			table.Standings[j].MatchLosses++
+		} else if gamesB > gamesA {
+			table.Standings[j].MatchWins++
+			table.Standings[i].MatchLosses++
+		}
+	}
+
+	for i := range table.Standings {
+		table.Standings[i].GameDiff = table.Standings[i].GameWins - table.Standings[i].GameLosses
+	}
+	sort.SliceStable(table.Standings, func(i, j int) bool {
+		a, b := table.Standings[i], table.Standings[j]
+		if a.MatchWins != b.MatchWins {
+			return a.MatchWins > b.MatchWins
+		}
+		return a.GameDiff > b.GameDiff
+	})
+	for i := range table.Standings {
+		table.Standings[i].Place = i + 1
+		if i > 0 {
+			a, b := table.Standings[i-1], table.Standings[i]
+			if a.MatchWins == b.MatchWins && a.GameDiff == b.GameDiff {
+				table.Standings[i].Place = a.Place
+			}
+		}
+	}
+	return table
+}
@@ -1,12 +1,39 @@
 package rlesports
 
+import (
+	"context"
+	"errors"
+)
+
+// Storage keeps tournaments and player names between runs. Reading something that hasn't been
+// saved yet, like a new tournament, is an error wrapping ErrNotFound; any other error means the
+// storage itself couldn't be read or written.
 type Storage interface {
-	GetTournament(*Tournament, *TournamentLPMetadata) error
-	SaveTournament(Tournament, TournamentLPMetadata)
-	GetAllTournaments() []Tournament
-
-	GetProcessedPlayers() ([]string, error)
-	GetPlayerNames() (map[string]string, error)
-	SaveProcessedPlayers([]string)
-	SavePlayerNames(map[string]string)
+	GetTournament(context.Context, *Tournament, *TournamentLPMetadata) error
+	SaveTournament(context.Context, Tournament, TournamentLPMetadata) error
+	GetAllTournaments(ctx context.Context) ([]Tournament, error)
+	GetTournamentsByRegion(ctx context.Context, region Region) ([]Tournament, error)
+	GetStandings(ctx context.Context, name string) ([]GroupTable, error)
+
+	GetProcessedPlayers(ctx context.Context) ([]string, error)
+	GetPlayerNames(ctx context.Context) (map[string]string, error)
+	SaveProcessedPlayers(context.Context, []string) error
+	SavePlayerNames(context.Context, map[string]string) error
+}
+
+// ErrNotFound is returned for things that haven't been saved yet
+var ErrNotFound = errors.New("not found")
+
+// StorageError is a failure to read or write storage, as opposed to fetching or parsing from
+// Liquipedia. Updates stop at the first one, since everything after it would fail the same way.
+type StorageError struct {
+	Err error
+}
+
+func (e *StorageError) Error() string {
+	return "storage: " + e.Err.Error()
+}
+
+func (e *StorageError) Unwrap() error {
+	return e.Err
 }
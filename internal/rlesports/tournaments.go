@@ -1,7 +1,10 @@
 package rlesports
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 )
 
 const (
@@ -41,54 +44,201 @@ func dbg(name string, needTeams bool, needDetails bool) {
 	fmt.Println(name, teamsString, detailsString)
 }
 
-func UpdateTournament(storage Storage, tournament Tournament, forceUpload bool) {
+// UpdateTournament fetches whatever details are missing or out of date for the given tournament
+// and saves it. Nothing is saved if any of the fetches fail.
+func UpdateTournament(ctx context.Context, client *LiquipediaClient, storage Storage, tournament Tournament, forceUpload bool) error {
+	infos, err := client.FetchPageInfo(ctx, []string{tournament.Name})
+	if err != nil {
+		return err
+	}
+	return updateTournament(ctx, client, storage, tournament, infos[tournament.Name], forceUpload)
+}
+
+// updateTournament does the work of UpdateTournament given the page's latest revision. An empty
+// info (e.g. if the batched revision check failed) is checked again on its own, and if that fails
+// too only missing fields are filled in.
+func updateTournament(ctx context.Context, client *LiquipediaClient, storage Storage, tournament Tournament, info PageInfo, forceUpload bool) error {
 	updatedTourney := tournament
 	tourneyMetadata := TournamentLPMetadata{ParticipationSection: -1}
 
-	err := storage.GetTournament(&updatedTourney, &tourneyMetadata)
+	err := storage.GetTournament(ctx, &updatedTourney, &tourneyMetadata)
+	notSaved := errors.Is(err, ErrNotFound)
+	if err != nil && !notSaved {
+		return &StorageError{err}
+	}
+
+	// Retrying the revision check is much cheaper than refetching a page that probably hasn't changed
+	if info.RevisionID == 0 && !info.Missing {
+		infos, err := client.FetchPageInfo(ctx, []string{tournament.Name})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			fmt.Println("Unable to check revision of", tournament.Name, err)
+		} else {
+			info = infos[tournament.Name]
+		}
+	}
+	if info.Missing {
+		return fmt.Errorf("%v: %w", tournament.Name, ErrPageMissing)
+	}
+
+	// 0. If the page has been edited since we last parsed it, everything needs to be re-parsed.
+	// Note that tournaments stored before we tracked revisions count as changed.
+	revisionChanged := info.RevisionID != 0 && info.RevisionID != tourneyMetadata.RevisionID
 
 	// 1. Check to see if this tournament has been cached, and if so, cached correctly. There
 	// are various checks here
 	// 1.a Infobox details
-	needInfobox := forceUpload || err != nil || updatedTourney.Start == "" || updatedTourney.End == "" || updatedTourney.Region == RegionNone
+	needInfobox := forceUpload || notSaved || revisionChanged || updatedTourney.Start.IsZero() || updatedTourney.End.IsZero()
 	// 1.b Team details
-	needTeams := forceUpload || err != nil || areTeamsIncomplete(updatedTourney)
+	needTeams := forceUpload || notSaved || revisionChanged || areTeamsIncomplete(updatedTourney)
+	// 1.c Results, which are spread over the whole page. Placements are stored on teams, so
+	// these are needed whenever teams are re-parsed too. Otherwise the whole page is only refetched
+	// if it's known to have changed since results were parsed.
+	needResults := forceUpload || notSaved || needTeams ||
+		(info.RevisionID != 0 && info.RevisionID != tourneyMetadata.ResultsRevisionID)
+
+	// Teams take the tournament's region if it has exactly one. Otherwise (e.g. LANs) each team's
+	// region comes from its qualifier. Stored regions come from whichever teams were parsed last
+	// time, so unless the skeleton says, the infobox has the final word whenever teams are re-parsed.
+	regions := tournament.Regions
+	if len(regions) == 0 {
+		regions = updatedTourney.Regions
+		needInfobox = needInfobox || needTeams
+	}
+	teamsRegion := RegionWorld
+	if len(regions) == 1 {
+		teamsRegion = regions[0]
+	}
 
 	dbg(tournament.Name, needTeams, needInfobox)
 
-	// 2. Fetch needed data from API
-	// 2.a Infobox: fetch first because team information depends on region
+	// Fetch the exact revision we checked if we can, so that cached responses are never stale
+	fetchSection := func(section int) (string, error) {
+		if info.RevisionID != 0 {
+			return client.FetchRevisionSection(ctx, info.RevisionID, section)
+		}
+		return client.FetchSection(ctx, tournament.Name, section)
+	}
+	fetchPage := func() (string, error) {
+		if info.RevisionID != 0 {
+			return client.FetchRevision(ctx, info.RevisionID)
+		}
+		return client.FetchPage(ctx, tournament.Name)
+	}
+
+	// 2. Fetch needed data from API. Teams are only ever needed along with results, and results
+	// need the whole page, so everything is parsed from that one page rather than fetching the
+	// infobox and participants sections on their own as well.
+	var page string
+	if needResults {
+		if page, err = fetchPage(); err != nil {
+			return err
+		}
+	}
+	// 2.a Infobox: parsed first because team information depends on region
 	if needInfobox {
-		wikitext := FetchSection(tournament.Name, InfoboxSectionIndex)
-		updatedTourney.Start, updatedTourney.End, updatedTourney.Region = ParseStartEndRegion(wikitext)
+		wikitext := page
+		if !needResults {
+			if wikitext, err = fetchSection(InfoboxSectionIndex); err != nil {
+				return err
+			}
+		}
+		var region Region
+		updatedTourney.Start, updatedTourney.End, region = ParseStartEndRegion(wikitext)
+		if len(tournament.Regions) == 0 && region != RegionNone {
+			teamsRegion = region
+			if region != RegionWorld && len(updatedTourney.Regions) == 0 {
+				updatedTourney.Regions = []Region{region}
+			}
+		}
 	}
 	// 2.b Teams
 	if needTeams {
-		if tourneyMetadata.ParticipationSection <= 0 {
-			// Need to find the right section for participants
-			allSections := FetchSections(tournament.Name)
-			tourneyMetadata.ParticipationSection = FindSectionIndex(allSections, PlayersSectionTitle)
-		}
-
+		var wikitext string
+		tourneyMetadata.ParticipationSection, wikitext = FindSection(page, PlayersSectionTitle)
 		if tourneyMetadata.ParticipationSection < 0 {
 			fmt.Println("Unable to find participants section for", tournament.Name)
 		} else {
-			wikitext := FetchSection(tournament.Name, tourneyMetadata.ParticipationSection)
-			updatedTourney.Teams = ParseTeams(wikitext, updatedTourney.Region)
+			updatedTourney.Teams = ParseTeams(wikitext, teamsRegion)
+			if regions := RegionsOf(updatedTourney.Teams); len(regions) > 0 {
+				updatedTourney.Regions = regions
+			}
+		}
+	}
+
+	// 2.c Results
+	if needResults {
+		updatedTourney.Matches = ParseMatches(page)
+		updatedTourney.Groups = ParseGroupTables(page)
+		for _, placing := range ApplyPlacements(updatedTourney.Teams, ParsePlacements(page)) {
+			fmt.Println("No team for placing", placing, "in", tournament.Name)
 		}
+		tourneyMetadata.ResultsRevisionID = info.RevisionID
 	}
 
 	// TODO: get images for teams
 
 	// 3. Upload the tournament
-	if needTeams || needInfobox {
-		storage.SaveTournament(updatedTourney, tourneyMetadata)
+	if needTeams || needInfobox || needResults {
+		if info.RevisionID != 0 {
+			tourneyMetadata.RevisionID = info.RevisionID
+			tourneyMetadata.Touched = info.Touched
+		}
+		if err := storage.SaveTournament(ctx, updatedTourney, tourneyMetadata); err != nil {
+			return &StorageError{err}
+		}
 	}
+	return nil
 }
 
-// UpdateTournaments goes through saved tournaments and updates fields that are missing.
-func UpdateTournaments(storage Storage, maxSeason int, forceUpload bool) {
-	for _, tournament := range TournamentSkeletons(maxSeason) {
-		UpdateTournament(storage, tournament, forceUpload)
+// UpdateTournaments goes through the first maxSeasons seasons of each series and updates fields
+// that are missing, as well as any tournaments whose Liquipedia page has a new revision. Pass
+// RegisteredSeries() to update everything. A tournament that fails to update is skipped so that the
+// rest of the run can continue, and the run fails at the end if any did. Only cancellation of ctx
+// or a StorageError stops the run early. A summary is printed either way.
+func UpdateTournaments(ctx context.Context, client *LiquipediaClient, storage Storage, series []Series, maxSeasons int, forceUpload bool) error {
+	var skeletons []Tournament
+	for _, s := range series {
+		tournaments, err := s.Skeletons(maxSeasons)
+		if err != nil {
+			return fmt.Errorf("%v: %w", s.Name(), err)
+		}
+		skeletons = append(skeletons, tournaments...)
+	}
+
+	// One batched revision check up front instead of one per tournament
+	names := make([]string, 0, len(skeletons))
+	for _, tournament := range skeletons {
+		names = append(names, tournament.Name)
+	}
+	infos, err := client.FetchPageInfo(ctx, names)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		fmt.Println("Unable to check revisions, only filling in missing details", err)
+	}
+
+	report := UpdateReport{Kind: "tournaments"}
+	defer report.Summary(os.Stdout)
+	for _, tournament := range skeletons {
+		err := updateTournament(ctx, client, storage, tournament, infos[tournament.Name], forceUpload)
+		if err == nil {
+			report.Succeeded++
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var storageErr *StorageError
+		if errors.As(err, &storageErr) {
+			report.Fail(tournament.Name, err)
+			return err
+		}
+		fmt.Println("Skipping", tournament.Name, err)
+		report.Fail(tournament.Name, err)
 	}
+	return report.Err()
 }
@@ -0,0 +1,123 @@
+package rlesports
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUpdateTournamentUnknownRevision(t *testing.T) {
+	const name = "Rocket League Championship Series/Season 1/North America/Qualifier 1"
+
+	for _, tc := range []struct {
+		desc    string
+		revid   int64
+		fetches int
+	}{
+		// The page hasn't changed since it was parsed
+		{"unchanged", 5, 0},
+		// Still unknown, so only missing details would be filled in
+		{"unknown", 0, 0},
+		// Edited since, so everything is parsed again from a single fetch of the whole page
+		{"changed", 6, 1},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			inTempDir(t)
+			ctx := context.Background()
+			var storage JsonStorage
+
+			stored := Tournament{
+				Name:    name,
+				Regions: []Region{RegionNorthAmerica},
+				Start:   NewDate(2016, time.April, 2),
+				End:     NewDate(2016, time.April, 3),
+				Teams:   []Team{{Name: "iBUYPOWER Cosmic", Players: []string{"Kronovi", "Lachinio", "Gambit"}, Region: RegionNorthAmerica}},
+			}
+			if err := storage.SaveTournament(ctx, stored, TournamentLPMetadata{ParticipationSection: 2, RevisionID: 5, ResultsRevisionID: 5}); err != nil {
+				t.Fatal(err)
+			}
+
+			revisionChecks, fetches := 0, 0
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("action") == "query" {
+					revisionChecks++
+					if tc.revid == 0 {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					fmt.Fprintf(w, `{"query":{"pages":{"1":{"title":%q,"lastrevid":%d}}}}`, name, tc.revid)
+					return
+				}
+				fetches++
+				if r.URL.Query().Get("prop") == "sections" {
+					fmt.Fprint(w, `{"parse":{"sections":[{"line":"Participants","index":"2"}]}}`)
+					return
+				}
+				fmt.Fprint(w, `{"parse":{"wikitext":{"*":""}}}`)
+			})
+
+			// As if the batched revision check had failed
+			if err := updateTournament(ctx, client, storage, Tournament{Name: name}, PageInfo{}, false); err != nil {
+				t.Fatal(err)
+			}
+			if revisionChecks != 1 || fetches != tc.fetches {
+				t.Errorf("%d revision checks and %d fetches, want 1 and %d", revisionChecks, fetches, tc.fetches)
+			}
+		})
+	}
+}
+
+func TestUpdateTournamentTeamsRegion(t *testing.T) {
+	const name = "Rocket League Championship Series/Season 1/North America/Qualifier 1"
+	page, err := os.ReadFile(filepath.Join("testdata", "tournaments", "s1_na_qualifier.wikitext"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inTempDir(t)
+	ctx := context.Background()
+	var storage JsonStorage
+
+	// Saved with its dates but a team that still needs its region
+	stored := Tournament{
+		Name:    name,
+		Regions: []Region{RegionNorthAmerica},
+		Start:   NewDate(2016, time.April, 2),
+		End:     NewDate(2016, time.April, 3),
+		Teams:   []Team{{Name: "iBUYPOWER Cosmic", Players: []string{"Kronovi", "Lachinio", "0ver Zer0"}}},
+	}
+	if err := storage.SaveTournament(ctx, stored, TournamentLPMetadata{ParticipationSection: 2, RevisionID: 5, ResultsRevisionID: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("action") == "query" {
+			fmt.Fprintf(w, `{"query":{"pages":{"1":{"title":%q,"lastrevid":5}}}}`, name)
+			return
+		}
+		body, _ := json.Marshal(map[string]interface{}{"parse": map[string]interface{}{"wikitext": map[string]string{"*": string(page)}}})
+		w.Write(body)
+	})
+
+	// As with `tournaments update`, where only the name is known
+	if err := UpdateTournament(ctx, client, storage, Tournament{Name: name}, false); err != nil {
+		t.Fatal(err)
+	}
+	updated := Tournament{Name: name}
+	var metadata TournamentLPMetadata
+	if err := storage.GetTournament(ctx, &updated, &metadata); err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.Teams) == 0 {
+		t.Fatal("no teams")
+	}
+	for _, team := range updated.Teams {
+		if team.Region != RegionNorthAmerica {
+			t.Errorf("%v: region %v", team.Name, team.Region)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package rlesports
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 // Try to keep this in sync with `types.ts` in the frontend please.
 
 // Team is a single team
@@ -9,23 +14,183 @@ type Team struct {
 	Subs    []string `json:"subs,omitempty"`
 	Region  Region   `json:"region,omitempty"`
 	Color   string   `json:"color,omitempty"`
+	Image   string   `json:"image,omitempty"`
+	// Roster has everyone on the team card, including the coach, with more detail than Players/Subs
+	Roster []RosterEntry `json:"roster,omitempty"`
+	// Placement and Prize are the team's final result in the tournament, if known
+	Placement *Placement `json:"placement,omitempty"`
+	Prize     *Prize     `json:"prize,omitempty"`
+	Won       bool       `json:"won,omitempty"`
+}
+
+// Placement is the range of places a team finished in, e.g. 3rd-4th
+type Placement struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+func (p Placement) String() string {
+	if p.End > p.Start {
+		return fmt.Sprintf("%s-%s", ordinal(p.Start), ordinal(p.End))
+	}
+	return ordinal(p.Start)
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+// Prize is an amount of prize money
+type Prize struct {
+	Amount float64 `json:"amount"`
+	// Currency is an ISO 4217 code such as USD
+	Currency string `json:"currency"`
+}
+
+// RosterRole is what a person was on a team's roster for
+type RosterRole string
+
+// Roster roles
+const (
+	RolePlayer RosterRole = "player"
+	RoleSub    RosterRole = "sub"
+	RoleCoach  RosterRole = "coach"
+)
+
+// RosterEntry is a single person on a team's roster
+type RosterEntry struct {
+	// Name is the name as displayed on the team card
+	Name string `json:"name"`
+	// Link is the person's Liquipedia page, if it's different from their name
+	Link string     `json:"link,omitempty"`
+	Flag string     `json:"flag,omitempty"`
+	Role RosterRole `json:"role"`
+}
+
+// Page is the Liquipedia page title for the person
+func (e RosterEntry) Page() string {
+	if e.Link != "" {
+		return e.Link
+	}
+	return e.Name
 }
 
 // Tournament x
 type Tournament struct {
-	// TODO move this to Regions so we can have multiple
-	Region Region `json:"region"`
+	// Regions are where the teams come from: one region for regional events, several for LANs
+	// with qualifiers from all over
+	Regions []Region `json:"regions"`
+	// Series is the registered series the tournament is part of, e.g. "RLCS", and Season is the
+	// season within it
+	Series string `json:"series,omitempty"`
 	Season string `json:"season"`
-	Name   string `json:"name"`
-	Start  string `json:"start"`
-	End    string `json:"end"`
-	Teams  []Team `json:"teams"`
+	// Tier is Liquipedia's tier for the tournament, e.g. "S-Tier", if known
+	Tier  string      `json:"tier,omitempty"`
+	Name  string      `json:"name"`
+	Start PartialDate `json:"start"`
+	End   PartialDate `json:"end"`
+	Teams []Team      `json:"teams"`
+	// Matches are all of the series played, from brackets and match lists
+	Matches []Match `json:"matches,omitempty"`
+	// Groups are the standings of group stages and league play
+	Groups []GroupTable `json:"groups,omitempty"`
+}
+
+// UnmarshalJSON also reads older files that have a single "region" instead of "regions". Worlds
+// events used to be stored as RegionWorld, so their regions come from their teams.
+func (t *Tournament) UnmarshalJSON(data []byte) error {
+	type tournament Tournament
+	var v struct {
+		tournament
+		Region Region `json:"region"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*t = Tournament(v.tournament)
+	if len(t.Regions) == 0 {
+		if v.Region != RegionNone && v.Region != RegionWorld {
+			t.Regions = []Region{v.Region}
+		} else {
+			t.Regions = RegionsOf(t.Teams)
+		}
+	}
+	return nil
+}
+
+// InRegion reports whether any of the tournament's teams come from region
+func (t Tournament) InRegion(region Region) bool {
+	for _, r := range t.Regions {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
+
+// GroupTable is the standings of a single group or league
+type GroupTable struct {
+	Title     string     `json:"title,omitempty"`
+	Standings []Standing `json:"standings"`
+}
+
+// Standing is a team's record in a group
+type Standing struct {
+	Team        string `json:"team"`
+	Place       int    `json:"place"`
+	MatchWins   int    `json:"matchWins"`
+	MatchLosses int    `json:"matchLosses"`
+	GameWins    int    `json:"gameWins"`
+	GameLosses  int    `json:"gameLosses"`
+	GameDiff    int    `json:"gameDiff"`
+}
+
+// Match is a single series between two teams
+type Match struct {
+	// Round is the bracket round or section heading the match was listed under
+	Round  string `json:"round,omitempty"`
+	Date   string `json:"date,omitempty"`
+	BestOf int    `json:"bestOf,omitempty"`
+	// Teams are the two opponents; Score is the number of games each of them won
+	Teams [2]string `json:"teams"`
+	Score [2]int    `json:"score"`
+	// Winner is 1 or 2 for the winning team, or 0 if there isn't one (yet)
+	Winner int    `json:"winner,omitempty"`
+	Games  []Game `json:"games,omitempty"`
+}
+
+// Game is a single game within a match
+type Game struct {
+	Map string `json:"map,omitempty"`
+	// Score is the number of goals each team scored
+	Score    [2]int `json:"score"`
+	Winner   int    `json:"winner,omitempty"`
+	Overtime bool   `json:"overtime,omitempty"`
 }
 
 // TournamentLPMetadata stores metadata that is only needed in relation to Liquipedia
 type TournamentLPMetadata struct {
 	// ParticipationSection indicates the section index that corresponds to the "Participants" section on the Tournament LP page
 	ParticipationSection int `json:"participantSection"`
+	// RevisionID is the Liquipedia revision that the stored tournament was parsed from
+	RevisionID int64 `json:"revid,omitempty"`
+	// Touched is the page's last-touched timestamp as of that revision
+	Touched string `json:"touched,omitempty"`
+	// ResultsRevisionID is the revision that results (matches etc.) were parsed from. These come from
+	// the whole page, so they're tracked separately from the sections above.
+	ResultsRevisionID int64 `json:"resultsRevid,omitempty"`
 }
 
 // Section x
@@ -34,53 +199,69 @@ type Section struct {
 	Tournaments []Tournament `json:"tournaments"`
 }
 
-// RlcsSeason x
+// Split is part of a season from RLCS X onwards, e.g. "Fall", with regional events in sections and
+// an international (or, in RLCS X, regional) Major to finish
+type Split struct {
+	Name     string    `json:"name"`
+	Sections []Section `json:"sections"`
+	Major    *Section  `json:"major,omitempty"`
+}
+
+// RlcsSeason x. Seasons with splits only have season-wide events, like Worlds, in Sections.
 type RlcsSeason struct {
 	Season   string    `json:"season"`
+	Splits   []Split   `json:"splits,omitempty"`
 	Sections []Section `json:"sections"`
 }
 
-// Region is represented by an 8-bit unsigned int
-type Region uint8
+// MembershipKind is how a player was on a team, from the annotations in their team history
+type MembershipKind string
 
-// Defined regions
+// Defined membership kinds
 const (
-	RegionNone Region = iota
-	RegionWorld
-	RegionNorthAmerica
-	RegionEurope
-	RegionOceania
-	RegionSouthAmerica
+	MembershipPlayer     MembershipKind = "player"
+	MembershipInactive   MembershipKind = "inactive"
+	MembershipLoan       MembershipKind = "loan"
+	MembershipSubstitute MembershipKind = "substitute"
+	MembershipCoach      MembershipKind = "coach"
 )
 
-func (r Region) String() string {
-	switch r {
-	case RegionNone:
-		return "None"
-	case RegionWorld:
-		return "World"
-	case RegionNorthAmerica:
-		return "North America"
-	case RegionEurope:
-		return "Europe"
-	case RegionOceania:
-		return "Oceania"
-	case RegionSouthAmerica:
-		return "South America"
-	}
-	return ""
-}
-
-// Membership is a team membership for a player
+// Membership is a team membership for a player. Leave is unknown for current teams.
 type Membership struct {
-	Join  string `json:"join"`
-	Leave string `json:"leave"`
-	Team  string `json:"team"`
+	Join  PartialDate    `json:"join"`
+	Leave PartialDate    `json:"leave"`
+	Team  string         `json:"team"`
+	Kind  MembershipKind `json:"kind,omitempty"`
 }
 
-// Player has a name and set of memberships
+// PlayerStatus is whether a player is still competing
+type PlayerStatus string
+
+// Statuses as given in player infoboxes
+const (
+	StatusActive   PlayerStatus = "active"
+	StatusInactive PlayerStatus = "inactive"
+	StatusRetired  PlayerStatus = "retired"
+)
+
+// Player has a name and set of memberships, plus whatever else the infobox knows about them
 type Player struct {
 	Memberships  []Membership `json:"memberships"`
 	Name         string       `json:"name"`
 	AlternateIDs []string     `json:"alternateIDs"`
+	RealName     string       `json:"realName,omitempty"`
+	// Nationalities are the countries the player represents, e.g. "United States"
+	Nationalities []string     `json:"nationalities,omitempty"`
+	BirthDate     PartialDate  `json:"birthDate"`
+	Status        PlayerStatus `json:"status,omitempty"`
+	// Roles are lowercase, e.g. "player", "coach" or "caster"
+	Roles []string `json:"roles,omitempty"`
+	// Team is the player's current team
+	Team string `json:"team,omitempty"`
+	// Links are social media handles/URLs keyed by site, e.g. "twitter"
+	Links map[string]string `json:"links,omitempty"`
+	// HistoryAuto is set for pages whose team history comes from transfers, with
+	// |history={{TeamHistoryAuto}}. Their memberships aren't in the wikitext, so ParsePlayer leaves
+	// Memberships empty and FetchTeamHistory has to get them.
+	HistoryAuto bool `json:"-"`
 }
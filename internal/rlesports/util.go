@@ -3,20 +3,21 @@ package rlesports
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 )
 
-// WriteJSONFile writes any type of output to the specified file
+// WriteJSONFile marshals output into filename. Write then rename so that an interrupted save never
+// leaves a truncated file behind.
 func WriteJSONFile(output interface{}, filename string) error {
-	outputBytes, err := json.MarshalIndent(output, "", "  ")
+	data, err := json.MarshalIndent(output, "", indent)
 	if err != nil {
-		fmt.Println("Unable to marshal output", err)
-		return err
+		return fmt.Errorf("failed to marshal %v: %w", filename, err)
 	}
-	err = os.WriteFile(filename, outputBytes, 0644)
-	if err != nil {
-		fmt.Println("Unable to write out tournaments cache file", err)
+
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, data, fs.FileMode(0644)); err != nil {
 		return err
 	}
-	return nil
+	return os.Rename(tmp, filename)
 }
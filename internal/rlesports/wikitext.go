@@ -0,0 +1,330 @@
+package rlesports
+
+import (
+	"strconv"
+	"strings"
+)
+
+/* Wikitext tokenizer/parser. This understands just enough of the syntax to pull data out of
+templates reliably: templates with named/positional params, links, and plain text. Comments are
+dropped and everything else (tables, tags, formatting) is kept as text. */
+
+// Node is a piece of parsed wikitext: Text, *Template or *Link
+type Node interface {
+	node()
+}
+
+// Nodes is a sequence of parsed wikitext
+type Nodes []Node
+
+// Text is plain text, kept as is
+type Text string
+
+// Template is a {{Name|param|name=param}} transclusion
+type Template struct {
+	Name   string
+	Params []Param
+}
+
+// Param is a single template parameter. Positional params are named by their position, starting
+// at 1, just like MediaWiki does.
+type Param struct {
+	Name       string
+	Positional bool
+	Value      Nodes
+}
+
+// Link is an internal [[Target|Text]] link. Text is nil if the link has no display text.
+type Link struct {
+	Target string
+	Text   Nodes
+}
+
+func (Text) node()      {}
+func (*Template) node() {}
+func (*Link) node()     {}
+
+// maxWikitextDepth is how deeply templates and links can nest. Real pages stay well under it;
+// anything deeper is kept as text rather than recursing without bound.
+const maxWikitextDepth = 64
+
+// ParseWikitext parses wikitext into a tree. It never fails: anything it doesn't understand, such
+// as unbalanced brackets, is kept as text.
+func ParseWikitext(wikitext string) Nodes {
+	p := wikitextParser{src: wikitext}
+	return p.parseNodes()
+}
+
+type wikitextParser struct {
+	src   string
+	pos   int
+	depth int
+	// badLinks are positions already known not to start a link, so that nested unclosed links
+	// aren't retried over and over
+	badLinks map[int]bool
+}
+
+func (p *wikitextParser) rest() string {
+	return p.src[p.pos:]
+}
+
+// parseNodes parses until EOF or until one of stops is next
+func (p *wikitextParser) parseNodes(stops ...string) Nodes {
+	var nodes Nodes
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, Text(text.String()))
+			text.Reset()
+		}
+	}
+
+	for p.pos < len(p.src) {
+		rest := p.rest()
+		if hasAnyPrefix(rest, stops) {
+			break
+		}
+
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			// Comments disappear entirely, so text on either side of one ends up joined
+			if end := strings.Index(rest, "-->"); end >= 0 {
+				p.pos += end + len("-->")
+			} else {
+				p.pos = len(p.src)
+			}
+		case strings.HasPrefix(rest, "{{{"):
+			// Template arguments only mean something inside template definitions
+			if end := strings.Index(rest, "}}}"); end >= 0 {
+				text.WriteString(rest[:end+len("}}}")])
+				p.pos += end + len("}}}")
+			} else {
+				text.WriteString("{{{")
+				p.pos += len("{{{")
+			}
+		case strings.HasPrefix(rest, "{{") && p.depth < maxWikitextDepth:
+			flush()
+			nodes = append(nodes, p.parseTemplate())
+		case strings.HasPrefix(rest, "[[") && p.depth < maxWikitextDepth:
+			if link := p.parseLink(); link != nil {
+				flush()
+				nodes = append(nodes, link)
+			} else {
+				text.WriteString("[[")
+				p.pos += len("[[")
+			}
+		default:
+			text.WriteByte(rest[0])
+			p.pos++
+		}
+	}
+
+	flush()
+	return nodes
+}
+
+// parseTemplate parses a template starting at "{{". An unclosed template runs to the end of the
+// input, which keeps truncated sections usable.
+func (p *wikitextParser) parseTemplate() *Template {
+	p.depth++
+	defer func() { p.depth-- }()
+
+	p.pos += len("{{")
+	template := &Template{Name: strings.TrimSpace(p.parseNodes("|", "}}").Text())}
+
+	position := 0
+	for p.pos < len(p.src) {
+		if strings.HasPrefix(p.rest(), "}}") {
+			p.pos += len("}}")
+			break
+		}
+		// Otherwise we're at a "|"
+		p.pos++
+
+		param := splitParam(p.parseNodes("|", "}}"))
+		if param.Positional {
+			position++
+			param.Name = strconv.Itoa(position)
+		}
+		template.Params = append(template.Params, param)
+	}
+
+	return template
+}
+
+// splitParam turns "name=value" into a named param. The "=" has to come before any nested
+// templates or links, otherwise the param is positional.
+func splitParam(value Nodes) Param {
+	if len(value) > 0 {
+		if text, ok := value[0].(Text); ok {
+			if eq := strings.IndexByte(string(text), '='); eq >= 0 {
+				rest := append(Nodes{}, value[1:]...)
+				if after := text[eq+1:]; after != "" {
+					rest = append(Nodes{after}, rest...)
+				}
+				return Param{Name: strings.TrimSpace(string(text[:eq])), Value: rest}
+			}
+		}
+	}
+	return Param{Positional: true, Value: value}
+}
+
+// parseLink parses a link starting at "[[", or returns nil (without consuming anything) if there
+// isn't a well-formed link here
+func (p *wikitextParser) parseLink() *Link {
+	start := p.pos
+	if p.badLinks[start] {
+		return nil
+	}
+	fail := func() *Link {
+		if p.badLinks == nil {
+			p.badLinks = make(map[int]bool)
+		}
+		p.badLinks[start] = true
+		p.pos = start
+		return nil
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+
+	rest := p.rest()[len("[["):]
+
+	// The target is plain text and has to end on the same line
+	end := strings.IndexAny(rest, "|]\n{}[")
+	if end < 0 || (rest[end] != '|' && !strings.HasPrefix(rest[end:], "]]")) {
+		return fail()
+	}
+	link := &Link{Target: strings.TrimSpace(rest[:end])}
+	p.pos += len("[[") + end
+
+	if p.src[p.pos] == '|' {
+		p.pos++
+		// Links can't contain links, so another "[[" means this one isn't closed
+		link.Text = p.parseNodes("]]", "}}", "\n", "[[")
+		if !strings.HasPrefix(p.rest(), "]]") {
+			return fail()
+		}
+		// [[Target|]] is valid and displays nothing
+		if link.Text == nil {
+			link.Text = Nodes{}
+		}
+	}
+	p.pos += len("]]")
+	return link
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Text renders nodes as plain text: links become their display text and templates are dropped
+func (ns Nodes) Text() string {
+	var b strings.Builder
+	for _, n := range ns {
+		switch n := n.(type) {
+		case Text:
+			b.WriteString(string(n))
+		case *Link:
+			if n.Text != nil {
+				b.WriteString(n.Text.Text())
+			} else {
+				b.WriteString(n.Target)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Walk calls fn on every template in nodes, including ones nested in params and links, in document
+// order
+func (ns Nodes) Walk(fn func(*Template)) {
+	for _, n := range ns {
+		switch n := n.(type) {
+		case *Template:
+			fn(n)
+			for _, param := range n.Params {
+				param.Value.Walk(fn)
+			}
+		case *Link:
+			n.Text.Walk(fn)
+		}
+	}
+}
+
+// Templates finds all templates (including nested ones) with any of the given names
+func (ns Nodes) Templates(names ...string) []*Template {
+	var templates []*Template
+	ns.Walk(func(t *Template) {
+		if t.Is(names...) {
+			templates = append(templates, t)
+		}
+	})
+	return templates
+}
+
+// Links finds all links (including ones nested in templates) in document order
+func (ns Nodes) Links() []*Link {
+	var links []*Link
+	for _, n := range ns {
+		switch n := n.(type) {
+		case *Template:
+			for _, param := range n.Params {
+				links = append(links, param.Value.Links()...)
+			}
+		case *Link:
+			links = append(links, n)
+			links = append(links, n.Text.Links()...)
+		}
+	}
+	return links
+}
+
+// normalizeName makes template and param names comparable the way MediaWiki does, ignoring case
+// and treating underscores as spaces
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(name, "_", " ")), " "))
+}
+
+// Is reports whether the template has any of the given names
+func (t *Template) Is(names ...string) bool {
+	name := normalizeName(t.Name)
+	for _, n := range names {
+		if name == normalizeName(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPrefix reports whether the template's name starts with prefix, e.g. "Infobox"
+func (t *Template) HasPrefix(prefix string) bool {
+	return strings.HasPrefix(normalizeName(t.Name), normalizeName(prefix))
+}
+
+// ParamValue returns the nodes of the named param. If the param is given more than once, the last
+// one wins, as in MediaWiki.
+func (t *Template) ParamValue(name string) (Nodes, bool) {
+	for i := len(t.Params) - 1; i >= 0; i-- {
+		if t.Params[i].Name == name {
+			return t.Params[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// Param returns the named param as trimmed plain text, or "" if it isn't there
+func (t *Template) Param(name string) string {
+	value, _ := t.ParamValue(name)
+	return strings.TrimSpace(value.Text())
+}
+
+// HasParam reports whether the named param is given at all, even if empty
+func (t *Template) HasParam(name string) bool {
+	_, ok := t.ParamValue(name)
+	return ok
+}
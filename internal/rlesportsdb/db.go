@@ -7,6 +7,8 @@ import (
 	"os"
 	"time"
 
+	"github.com/sarangjo/rlesports/internal/rlesports"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -43,20 +45,32 @@ func InitializeClient() {
 func UploadTournaments(data []TournamentDoc) {
 	tournaments := db.Collection("tournaments")
 
-	models := make([]mongo.WriteModel, len(data))
+	models := make([]mongo.WriteModel, 0, len(data))
 
 	for _, tournament := range data {
+		tournament.fillRegions()
 		models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.M{"name": tournament.Name}).SetReplacement(tournament).SetUpsert(true))
 	}
 
 	tournaments.BulkWrite(context.Background(), models)
 }
 
-// GetTournaments returns a list of all tournaments in the db
-func GetTournaments() []TournamentDoc {
+// GetTournaments returns a list of all tournaments in the db, or only the ones with teams from
+// region unless it's RegionNone
+func GetTournaments(region rlesports.Region) []TournamentDoc {
 	tournaments := db.Collection("tournaments")
 	opts := options.Find().SetSort(bson.D{primitive.E{Key: "start", Value: 1}})
-	cur, err := tournaments.Find(context.Background(), bson.D{}, opts)
+	filter := bson.D{}
+	if region != rlesports.RegionNone {
+		// Documents uploaded before regions only have a single region, or none at all for Worlds
+		// events, so their teams are checked too. Each of these matches any element of an array.
+		filter = bson.D{primitive.E{Key: "$or", Value: bson.A{
+			bson.D{primitive.E{Key: "regions", Value: region}},
+			bson.D{primitive.E{Key: "region", Value: region}},
+			bson.D{primitive.E{Key: "teams.region", Value: region}},
+		}}}
+	}
+	cur, err := tournaments.Find(context.Background(), filter, opts)
 	if err != nil {
 		fmt.Println("Failed to find", err)
 		os.Exit(1)
@@ -66,6 +80,9 @@ func GetTournaments() []TournamentDoc {
 	if err = cur.All(context.TODO(), &results); err != nil {
 		log.Fatal(err)
 	}
+	for i := range results {
+		results[i].fillRegions()
+	}
 
 	return results
 }
@@ -74,14 +91,18 @@ func GetTournaments() []TournamentDoc {
 func GetTournament(t *TournamentDoc) error {
 	tournaments := db.Collection("tournaments")
 	filter := bson.M{"name": t.Name}
-	doc := tournaments.FindOne(context.Background(), filter)
-	return doc.Decode(t)
+	if err := tournaments.FindOne(context.Background(), filter).Decode(t); err != nil {
+		return err
+	}
+	t.fillRegions()
+	return nil
 }
 
 // UploadTournament uploads tournament by name
 func UploadTournament(t TournamentDoc) {
 	tournaments := db.Collection("tournaments")
 
+	t.fillRegions()
 	opts := options.Replace().SetUpsert(true)
 	filter := bson.M{"name": t.Name}
 	result, err := tournaments.ReplaceOne(context.Background(), filter, t, opts)
@@ -149,53 +149,51 @@ func filterByTournament(player rlesports.Player, seasons []rlesports.RlcsSeason,
 
 	// Go through each tournament and turn on the memberships that matter
 	for _, season := range seasons[seasonIdx:] {
-		for _, section := range season.Sections {
-			for _, t := range section.Tournaments {
-				// Okay. For this tournament, which memberships fit?
-				tourneyBitSet := make([]bool, len(player.Memberships))
-				// It is possible that we never found a match. In that case be more permissive. Note
-				// this is up to but NOT including
-				lastTeamMatch := len(player.Memberships)
-
-				// Note. We could have multiple memberships that overlap with this tournament.
-				for idx, membership := range player.Memberships {
-					// if membership.Leave != "" && membership.Leave < t.Start {
-					// 	// quit out early
-					// 	lowestMembership++
-					// 	if lowestMembership == len(player.Memberships) {
-					// 		break SeasonLoop
-					// 	}
-					// TODO change >= to > (e.g. Lemonpuppy * Radiance acquired right at the start
-					// of RLCS)
-					if membership.Join <= t.End && (membership.Leave == "" || membership.Leave >= t.Start) {
-						// First check passed: this lines up by time
-						for _, team := range t.Teams {
-							// Second check: we confirm that this player actually participated,
-							// using player names and alternate ID's
-							tourneyBitSet[idx] = tourneyBitSet[idx] || playerInTeam(player, team)
-							// } else {
-							// 	// Second check: we confirm that this player actually participated,
-							// 	// using player names and alternate ID's
-							// 	filterBitSet[idx] = filterBitSet[idx] || playerInTeam(player, team)
-							// }
-
-							// Third check: If the team name matches this membership, no further memberships
-							// can possibly match this tournament. Let's get out and move on to
-							// the next tournament and do this fun thing all over again!
-							if teamNameMatch(team.Name, membership.Team) {
-								lastTeamMatch = idx + 1
-							}
+		for _, t := range season.Tournaments() {
+			// Okay. For this tournament, which memberships fit?
+			tourneyBitSet := make([]bool, len(player.Memberships))
+			// It is possible that we never found a match. In that case be more permissive. Note
+			// this is up to but NOT including
+			lastTeamMatch := len(player.Memberships)
+
+			// Note. We could have multiple memberships that overlap with this tournament.
+			for idx, membership := range player.Memberships {
+				// if !membership.Leave.IsZero() && membership.Leave.Before(t.Start) {
+				// 	// quit out early
+				// 	lowestMembership++
+				// 	if lowestMembership == len(player.Memberships) {
+				// 		break SeasonLoop
+				// 	}
+				// TODO change >= to > (e.g. Lemonpuppy * Radiance acquired right at the start
+				// of RLCS)
+				if rlesports.Overlaps(membership.Join, membership.Leave, t.Start, t.End) {
+					// First check passed: this lines up by time
+					for _, team := range t.Teams {
+						// Second check: we confirm that this player actually participated,
+						// using player names and alternate ID's
+						tourneyBitSet[idx] = tourneyBitSet[idx] || playerInTeam(player, team)
+						// } else {
+						// 	// Second check: we confirm that this player actually participated,
+						// 	// using player names and alternate ID's
+						// 	filterBitSet[idx] = filterBitSet[idx] || playerInTeam(player, team)
+						// }
+
+						// Third check: If the team name matches this membership, no further memberships
+						// can possibly match this tournament. Let's get out and move on to
+						// the next tournament and do this fun thing all over again!
+						if teamNameMatch(team.Name, membership.Team) {
+							lastTeamMatch = idx + 1
 						}
 					}
 				}
+			}
 
-				// Okay we went through all memberships and evaluated which memberships line up. We
-				// also know the index of the *last* membership which matches the team name for this
-				// tournament. This means we can apply the bitmask only up till (and including) this
-				// last index
-				for idx, tourneyBit := range tourneyBitSet[:lastTeamMatch] {
-					filterBitSet[idx] = filterBitSet[idx] || tourneyBit
-				}
+			// Okay we went through all memberships and evaluated which memberships line up. We
+			// also know the index of the *last* membership which matches the team name for this
+			// tournament. This means we can apply the bitmask only up till (and including) this
+			// last index
+			for idx, tourneyBit := range tourneyBitSet[:lastTeamMatch] {
+				filterBitSet[idx] = filterBitSet[idx] || tourneyBit
 			}
 		}
 	}
@@ -223,7 +221,7 @@ func SmarterPlayers() {
 		for _, section := range season.Sections {
 			fmt.Println("\tSECTION", section.Name)
 			for _, tournament := range section.Tournaments {
-				fmt.Println("\t\tREGION", tournament.Region.String())
+				fmt.Println("\t\tREGIONS", tournament.Regions)
 				for _, team := range tournament.Teams {
 					fmt.Println("\t\t\tTEAM", team.Name)
 					for _, tname := range team.Players {
@@ -5,8 +5,10 @@ import "github.com/sarangjo/rlesports/internal/rlesports"
 // TournamentDoc describes a tournament as stored in the db
 type TournamentDoc struct {
 	// Metadata
-	Season string           `json:"season"`
-	Region rlesports.Region `json:"region,omitempty"`
+	Season  string             `json:"season"`
+	Regions []rlesports.Region `json:"regions,omitempty"`
+	// Region is only set on documents uploaded before Regions. Use Regions.
+	Region rlesports.Region `json:"-" bson:"region,omitempty"`
 	Index  int              `json:"index"`
 	// Liquipedia-specific details (cached so as to save API calls)
 	ParticipationSection int `json:"participantSection"`
@@ -17,3 +19,16 @@ type TournamentDoc struct {
 	End   string           `json:"end,omitempty"`
 	Teams []rlesports.Team `json:"teams"`
 }
+
+// fillRegions sets Regions on documents uploaded before there were Regions. Worlds events used to
+// be stored as RegionWorld, so their regions come from their teams.
+func (t *TournamentDoc) fillRegions() {
+	if len(t.Regions) == 0 {
+		if t.Region != rlesports.RegionNone && t.Region != rlesports.RegionWorld {
+			t.Regions = []rlesports.Region{t.Region}
+		} else {
+			t.Regions = rlesports.RegionsOf(t.Teams)
+		}
+	}
+	t.Region = rlesports.RegionNone
+}
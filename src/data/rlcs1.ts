@@ -5,7 +5,7 @@ export const tournaments: Tournament[] = [
     name: "RLCS S1 NA Qualifier 1",
     start: "2016-04-30",
     end: "2016-05-21",
-    region: Region.NORTH_AMERICA,
+    regions: [Region.NORTH_AMERICA],
     teams: [
       {
         name: "iBUYPOWER",
@@ -61,7 +61,7 @@ export const tournaments: Tournament[] = [
     name: "RLCS S1 NA Qualifier 2",
     start: "2016-06-25",
     end: "2016-07-09",
-    region: Region.NORTH_AMERICA,
+    regions: [Region.NORTH_AMERICA],
     teams: [
       {
         name: "iBUYPOWER",
@@ -117,7 +117,7 @@ export const tournaments: Tournament[] = [
     name: "RLCS S1 EU Qualifier 1",
     start: "2016-05-01",
     end: "2016-05-22",
-    region: Region.EUROPE,
+    regions: [Region.EUROPE],
     teams: [
       {
         name: "FlipSid3 Tactics",
@@ -173,7 +173,7 @@ export const tournaments: Tournament[] = [
     name: "RLCS S1 EU Qualifier 2",
     start: "2016-06-26",
     end: "2016-07-10",
-    region: Region.EUROPE,
+    regions: [Region.EUROPE],
     teams: [
       {
         name: "FlipSid3 Tactics",
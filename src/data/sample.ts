@@ -6,7 +6,7 @@ export const tournaments: Tournament[] = [
     name: "Tournament I",
     start: "2023-01-01",
     end: "2023-01-08",
-    region: Region.NORTH_AMERICA,
+    regions: [Region.NORTH_AMERICA],
     teams: [
       {
         name: "Team A",
@@ -26,7 +26,7 @@ export const tournaments: Tournament[] = [
     name: "Tournament II",
     start: "2023-02-01",
     end: "2023-02-08",
-    region: Region.NORTH_AMERICA,
+    regions: [Region.NORTH_AMERICA],
     teams: [
       {
         name: "Team A",
@@ -1,5 +1,6 @@
 // Types relating to the RL Esports overall data
 
+// "YYYY-MM-DD". Parts Liquipedia doesn't know are "??", e.g. "2016-04-??", and an unknown date is "".
 export type SimpleDate = string;
 
 export enum EventType {
@@ -12,15 +13,56 @@ export enum MembershipType {
   NOT_MEMBER,
 }
 
+// How a player was on a team, from the annotations in their team history
+export enum MembershipKind {
+  PLAYER = "player",
+  INACTIVE = "inactive",
+  LOAN = "loan",
+  SUBSTITUTE = "substitute",
+  COACH = "coach",
+}
+
 export interface Membership {
   team: string;
   join: SimpleDate;
   leave?: SimpleDate;
+  kind?: MembershipKind;
+}
+
+export enum PlayerStatus {
+  ACTIVE = "active",
+  INACTIVE = "inactive",
+  RETIRED = "retired",
 }
 
 export interface Player {
   name: string;
   memberships: Membership[];
+  alternateIDs?: string[];
+  realName?: string;
+  nationalities?: string[];
+  birthDate?: SimpleDate;
+  status?: PlayerStatus;
+  // Lowercase, e.g. "player", "coach" or "caster"
+  roles?: string[];
+  // Current team
+  team?: string;
+  // Social handles/URLs keyed by site, e.g. "twitter"
+  links?: Record<string, string>;
+}
+
+export enum RosterRole {
+  PLAYER = "player",
+  SUB = "sub",
+  COACH = "coach",
+}
+
+export interface RosterEntry {
+  name: string;
+  // Liquipedia page, if different from name
+  link?: string;
+  flag?: string;
+  role: RosterRole;
 }
 
 export interface Team {
@@ -33,14 +75,74 @@ export interface Team {
   won?: boolean;
   // Notion of team -> color being 1-to-1 is incorrect
   color?: string;
+  image?: string;
+  roster?: RosterEntry[];
+  placement?: Placement;
+  prize?: Prize;
+}
+
+// Range of places, e.g. 3rd-4th is { start: 3, end: 4 }
+export interface Placement {
+  start: number;
+  end: number;
+}
+
+export interface Prize {
+  amount: number;
+  // ISO 4217 code, e.g. USD
+  currency: string;
+}
+
+export interface Game {
+  map?: string;
+  // Goals scored by each team
+  score: [number, number];
+  // 1 or 2
+  winner?: number;
+  overtime?: boolean;
+}
+
+export interface Match {
+  round?: string;
+  date?: string;
+  bestOf?: number;
+  teams: [string, string];
+  // Games won by each team
+  score: [number, number];
+  // 1 or 2, missing if undecided
+  winner?: number;
+  games?: Game[];
+}
+
+export interface Standing {
+  team: string;
+  place: number;
+  matchWins: number;
+  matchLosses: number;
+  gameWins: number;
+  gameLosses: number;
+  gameDiff: number;
+}
+
+// A group stage or league table
+export interface GroupTable {
+  title?: string;
+  standings: Standing[];
 }
 
 export interface Tournament {
-  region: Region;
+  // One region for regional events, several for LANs
+  regions: Region[];
   name: string;
-  start: string;
-  end: string;
+  // The series it's part of, e.g. "RLCS" or "DreamHack Pro Circuit"
+  series?: string;
+  // Liquipedia tier, e.g. "S-Tier"
+  tier?: string;
+  start: SimpleDate;
+  end: SimpleDate;
   teams: Team[];
+  matches?: Match[];
+  groups?: GroupTable[];
 }
 
 export interface Section {
@@ -48,18 +150,32 @@ export interface Section {
   tournaments: Tournament[];
 }
 
+// From RLCS X onwards, seasons are split into e.g. Fall, Winter and Spring, each with its own
+// regional events and a Major
+export interface Split {
+  name: string;
+  sections: Section[];
+  major?: Section;
+}
+
 export interface RlcsSeason {
   season: string;
+  splits?: Split[];
+  // Only season-wide events, like Worlds, for seasons with splits
   sections: Section[];
 }
 
-// TODO remove "WORLD" and replace with a collection of regions
+// WORLD is only kept for older data; tournaments list their teams' regions instead
 export enum Region {
-  NONE = 0,
-  WORLD,
-  NORTH_AMERICA,
-  EUROPE,
-  OCEANIA,
-  SOUTH_AMERICA,
-  MIDDLE_EAST,
+  NONE = "",
+  WORLD = "world",
+  NORTH_AMERICA = "na",
+  EUROPE = "eu",
+  OCEANIA = "oce",
+  SOUTH_AMERICA = "sam",
+  MIDDLE_EAST = "mena",
+  ASIA_PACIFIC_NORTH = "apacn",
+  ASIA_PACIFIC_SOUTH = "apacs",
+  SUB_SAHARAN_AFRICA = "ssa",
+  ASIA = "asia",
 }
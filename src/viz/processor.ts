@@ -13,7 +13,7 @@ const uiTournamentToTournament = (
   name: tournament.name,
   start: tournament.start,
   end: tournament.end,
-  region: tournament.region,
+  regions: tournament.regions,
 
   // UI elements
   x: x(s2d(tournament.start)),